@@ -25,6 +25,17 @@ func task(ctx context.Context) error {
 	return nil
 }
 
+func hourlyTask(ctx context.Context) error {
+	e, err := cron.NewEvent(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Run hourly task:", e.Cron)
+	return nil
+}
+
 func main() {
+	// hourlyTask runs only for the "0 * * * *" trigger, and task runs for the others.
+	cron.Handle("0 * * * *", hourlyTask)
 	cron.ScheduleTask(task)
 }
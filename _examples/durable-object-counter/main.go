@@ -1,14 +1,18 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"net/http"
 
 	"github.com/syumai/workers"
 	"github.com/syumai/workers/cloudflare"
+	"github.com/syumai/workers/cloudflare/durableobject"
 )
 
 func main() {
+	durableobject.Register("Counter", NewCounter)
 	workers.Serve(&MyHandler{})
 }
 
@@ -19,7 +23,7 @@ func canHaveBody(method string) bool {
 type MyHandler struct{}
 
 func (_ *MyHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	COUNTER, err := cloudflare.NewDurableObjectNamespace("COUNTER")
+	COUNTER, err := cloudflare.NewDurableObjectNamespaceFromContext(req.Context(), "COUNTER")
 	if err != nil {
 		panic(err)
 	}
@@ -46,3 +50,45 @@ func (_ *MyHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 
 	w.Write([]byte("Durable object 'A' count: " + string(count)))
 }
+
+// Counter is a Durable Object which holds a counter value in its storage.
+type Counter struct {
+	storage *durableobject.Storage
+}
+
+func NewCounter(ctx context.Context, state *durableobject.State) http.Handler {
+	return &Counter{storage: state.Storage()}
+}
+
+// ServeHTTP handles HTTP requests sent to the Durable Object.
+func (c *Counter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	// Durable Object storage is automatically cached in-memory, so reading the
+	// same key every request is fast.
+	var value int
+	if _, err := c.storage.Get("value", &value, nil); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch req.URL.Path {
+	case "/increment":
+		value++
+	case "/decrement":
+		value--
+	case "/":
+		// Just serve the current value.
+	default:
+		http.NotFound(w, req)
+		return
+	}
+
+	// We don't have to worry about a concurrent request having modified the
+	// value in storage because "input gates" will automatically protect against
+	// unwanted concurrency. So, read-modify-write is safe.
+	if err := c.storage.Put("value", value, nil); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	fmt.Fprint(w, value)
+}
@@ -1,6 +1,6 @@
 module github.com/syumai/workers/_examples/hello
 
-go 1.21.3
+go 1.23.0
 
 require github.com/syumai/workers v0.0.0
 
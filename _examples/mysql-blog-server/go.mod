@@ -1,6 +1,6 @@
 module github.com/syumai/workers/_examples/mysql-blog-server
 
-go 1.21.3
+go 1.23.0
 
 require (
 	github.com/go-sql-driver/mysql v1.7.1
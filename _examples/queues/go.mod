@@ -1,6 +1,6 @@
 module github.com/syumai/workers/_examples/queues
 
-go 1.22.8
+go 1.23.0
 
 require github.com/syumai/workers v0.0.0
 
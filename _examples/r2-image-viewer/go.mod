@@ -1,6 +1,6 @@
 module github.com/syumai/workers/_examples/r2-image-viewer-tinygo
 
-go 1.21.3
+go 1.23.0
 
 require github.com/syumai/workers v0.0.0-00010101000000-000000000000
 
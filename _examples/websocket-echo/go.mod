@@ -0,0 +1,7 @@
+module github.com/syumai/workers/_examples/websocket-echo
+
+go 1.23.0
+
+require github.com/syumai/workers v0.0.0
+
+replace github.com/syumai/workers => ../../
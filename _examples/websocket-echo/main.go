@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/syumai/workers"
+	"github.com/syumai/workers/cloudflare/websocket"
+)
+
+func main() {
+	http.HandleFunc("/ws", func(w http.ResponseWriter, req *http.Request) {
+		conn, err := websocket.Upgrade(w, req)
+		if err != nil {
+			log.Println(err)
+			return
+		}
+		for {
+			typ, data, err := conn.ReadMessage()
+			if err != nil {
+				var closeErr *websocket.CloseError
+				if !errors.As(err, &closeErr) {
+					log.Println(err)
+				}
+				return
+			}
+			if err := conn.WriteMessage(typ, data); err != nil {
+				log.Println(err)
+				return
+			}
+		}
+	})
+	workers.Serve(nil) // use http.DefaultServeMux
+}
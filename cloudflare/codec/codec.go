@@ -0,0 +1,46 @@
+//go:build js && wasm
+
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"syscall/js"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// ErrUndefined is returned by Decode when the given JS value is undefined.
+var ErrUndefined = errors.New("codec: value is undefined")
+
+// Codec converts Go values to JS values and vice versa.
+// It is used to store Go values in the storages of the Workers runtime which accept structured-cloneable JS values.
+type Codec interface {
+	// Encode converts v into a JS value.
+	Encode(v any) (js.Value, error)
+	// Decode converts a JS value into the value pointed to by ptr.
+	Decode(v js.Value, ptr any) error
+}
+
+// JSON is a Codec which converts values through encoding/json.
+//   - Encoded values are plain JS objects, so they can be read from JS code as well.
+//   - Numbers are converted into JS numbers, so integers beyond 2^53 lose their precision.
+var JSON Codec = jsonCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Encode(v any) (js.Value, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return js.Value{}, err
+	}
+	return jsutil.JSONObject.Call("parse", string(b)), nil
+}
+
+func (jsonCodec) Decode(v js.Value, ptr any) error {
+	if v.IsUndefined() {
+		return ErrUndefined
+	}
+	s := jsutil.JSONObject.Call("stringify", v)
+	return json.Unmarshal([]byte(s.String()), ptr)
+}
@@ -0,0 +1,44 @@
+//go:build js && wasm
+
+package codec
+
+import (
+	"errors"
+	"syscall/js"
+	"testing"
+)
+
+type item struct {
+	Name  string   `json:"name"`
+	Count int      `json:"count"`
+	Tags  []string `json:"tags"`
+}
+
+func TestJSON(t *testing.T) {
+	want := item{Name: "a", Count: 3, Tags: []string{"x", "y"}}
+	v, err := JSON.Encode(want)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if got := v.Get("name").String(); got != "a" {
+		t.Errorf("encoded name = %q, want %q", got, "a")
+	}
+	if got := v.Get("count").Int(); got != 3 {
+		t.Errorf("encoded count = %d, want %d", got, 3)
+	}
+
+	var got item
+	if err := JSON.Decode(v, &got); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if got.Name != want.Name || got.Count != want.Count || len(got.Tags) != 2 {
+		t.Errorf("Decode() = %+v, want %+v", got, want)
+	}
+}
+
+func TestJSON_DecodeUndefined(t *testing.T) {
+	var got item
+	if err := JSON.Decode(js.Undefined(), &got); !errors.Is(err, ErrUndefined) {
+		t.Errorf("Decode() error = %v, want %v", err, ErrUndefined)
+	}
+}
@@ -0,0 +1,567 @@
+//go:build js && wasm
+
+package codec
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"syscall/js"
+	"time"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// StructuredClone is a Codec which converts Go values into structured-cloneable JS values directly.
+// Unlike JSON, binary data and times are kept as Uint8Array and Date.
+//
+// Go values are encoded as below, and decoded in the reverse way.
+//   - nil, nil pointers, nil slices and nil maps: null
+//   - bool, integers, floats and strings: boolean, number and string
+//   - []byte: Uint8Array (ArrayBuffer and typed arrays are accepted on decoding)
+//   - time.Time: Date
+//   - slices and arrays: Array
+//   - maps with string keys: Object (Map is accepted on decoding)
+//   - structs: Object whose keys are the field names or the names given by `json` tags. `omitempty` and `-` are supported.
+//   - js.Value: the value as is
+//
+// Decoding into `any` produces nil, bool, float64, string, []byte, time.Time, []any or map[string]any.
+// Encoding a value which refers to itself returns an error wrapping ErrCycle.
+var StructuredClone Codec = structuredCodec{}
+
+type structuredCodec struct{}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	jsValueType = reflect.TypeOf(js.Value{})
+	bytesType   = reflect.TypeOf([]byte(nil))
+
+	arrayBufferClass = js.Global().Get("ArrayBuffer")
+	mapClass         = js.Global().Get("Map")
+)
+
+func (structuredCodec) Encode(v any) (js.Value, error) {
+	if v == nil {
+		return js.Null(), nil
+	}
+	e := &encoder{visiting: map[visitKey]struct{}{}}
+	return e.encodeValue(reflect.ValueOf(v))
+}
+
+// ErrCycle is returned when a value to encode refers to itself.
+var ErrCycle = errors.New("codec: encountered a cycle")
+
+// encoder holds the state of encoding a value.
+type encoder struct {
+	// visiting holds the pointers, maps and slices being encoded to detect cycles.
+	visiting map[visitKey]struct{}
+}
+
+// visitKey identifies a pointer, map or slice. Slices are also identified by their length
+// since a slice and its subslice share the pointer without being a cycle.
+type visitKey struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// enter marks rv as being encoded, and returns the function to unmark it.
+func (e *encoder) enter(rv reflect.Value) (func(), error) {
+	key := visitKey{ptr: rv.Pointer(), typ: rv.Type()}
+	if rv.Kind() == reflect.Slice {
+		key.len = rv.Len()
+	}
+	if _, ok := e.visiting[key]; ok {
+		return nil, fmt.Errorf("%w via %s", ErrCycle, rv.Type())
+	}
+	e.visiting[key] = struct{}{}
+	return func() { delete(e.visiting, key) }, nil
+}
+
+func (e *encoder) encodeValue(rv reflect.Value) (js.Value, error) {
+	switch rv.Type() {
+	case timeType:
+		return jsutil.TimeToDate(rv.Interface().(time.Time)), nil
+	case jsValueType:
+		return rv.Interface().(js.Value), nil
+	}
+	switch rv.Kind() {
+	case reflect.Bool:
+		return js.ValueOf(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return js.ValueOf(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return js.ValueOf(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return js.ValueOf(rv.Float()), nil
+	case reflect.String:
+		return js.ValueOf(rv.String()), nil
+	case reflect.Interface:
+		if rv.IsNil() {
+			return js.Null(), nil
+		}
+		return e.encodeValue(rv.Elem())
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return js.Null(), nil
+		}
+		leave, err := e.enter(rv)
+		if err != nil {
+			return js.Value{}, err
+		}
+		defer leave()
+		return e.encodeValue(rv.Elem())
+	case reflect.Slice:
+		if rv.IsNil() {
+			return js.Null(), nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := rv.Bytes()
+			ua := jsutil.NewUint8Array(len(b))
+			js.CopyBytesToJS(ua, b)
+			return ua, nil
+		}
+		leave, err := e.enter(rv)
+		if err != nil {
+			return js.Value{}, err
+		}
+		defer leave()
+		return e.encodeArray(rv)
+	case reflect.Array:
+		return e.encodeArray(rv)
+	case reflect.Map:
+		if rv.IsNil() {
+			return js.Null(), nil
+		}
+		if rv.Type().Key().Kind() != reflect.String {
+			return js.Value{}, fmt.Errorf("codec: unsupported map key type: %s", rv.Type().Key())
+		}
+		leave, err := e.enter(rv)
+		if err != nil {
+			return js.Value{}, err
+		}
+		defer leave()
+		obj := jsutil.NewObject()
+		iter := rv.MapRange()
+		for iter.Next() {
+			v, err := e.encodeValue(iter.Value())
+			if err != nil {
+				return js.Value{}, err
+			}
+			obj.Set(iter.Key().String(), v)
+		}
+		return obj, nil
+	case reflect.Struct:
+		obj := jsutil.NewObject()
+		for _, f := range structFields(rv.Type()) {
+			fv, err := rv.FieldByIndexErr(f.index)
+			if err != nil {
+				// the field is promoted through a nil embedded pointer.
+				continue
+			}
+			if f.omitEmpty && fv.IsZero() {
+				continue
+			}
+			v, err := e.encodeValue(fv)
+			if err != nil {
+				return js.Value{}, fmt.Errorf("codec: field %s: %w", f.name, err)
+			}
+			obj.Set(f.name, v)
+		}
+		return obj, nil
+	}
+	return js.Value{}, fmt.Errorf("codec: unsupported type: %s", rv.Type())
+}
+
+func (e *encoder) encodeArray(rv reflect.Value) (js.Value, error) {
+	arr := jsutil.NewArray(rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		v, err := e.encodeValue(rv.Index(i))
+		if err != nil {
+			return js.Value{}, err
+		}
+		arr.SetIndex(i, v)
+	}
+	return arr, nil
+}
+
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	depth     int
+	tagged    bool
+}
+
+// structFields returns the encoded fields of the struct type in the same manner as encoding/json.
+//   - Fields of embedded structs without tags are promoted.
+//   - If several fields share a name, the shallowest one wins, then the tagged one.
+//     The fields are dropped if this still leaves more than one.
+func structFields(t reflect.Type) []structField {
+	type entry struct {
+		typ   reflect.Type
+		index []int
+	}
+	var fields []structField
+	visited := map[reflect.Type]bool{}
+	next := []entry{{typ: t}}
+	for depth := 0; len(next) > 0; depth++ {
+		current := next
+		next = nil
+		for _, e := range current {
+			if visited[e.typ] {
+				continue
+			}
+			for i := 0; i < e.typ.NumField(); i++ {
+				sf := e.typ.Field(i)
+				ft := sf.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if sf.Anonymous {
+					if !sf.IsExported() && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !sf.IsExported() {
+					continue
+				}
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, opts, _ := strings.Cut(tag, ",")
+				index := append(slices.Clone(e.index), i)
+				if name == "" && sf.Anonymous && ft.Kind() == reflect.Struct {
+					next = append(next, entry{typ: ft, index: index})
+					continue
+				}
+				tagged := name != ""
+				if !tagged {
+					name = sf.Name
+				}
+				fields = append(fields, structField{
+					name:      name,
+					index:     index,
+					omitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+					depth:     depth,
+					tagged:    tagged,
+				})
+			}
+		}
+		// types are marked after the whole depth so that a type embedded twice at the same depth makes its fields ambiguous.
+		for _, e := range current {
+			visited[e.typ] = true
+		}
+	}
+
+	slices.SortStableFunc(fields, func(a, b structField) int {
+		if c := strings.Compare(a.name, b.name); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.depth, b.depth); c != 0 {
+			return c
+		}
+		if a.tagged != b.tagged {
+			if a.tagged {
+				return -1
+			}
+			return 1
+		}
+		return slices.Compare(a.index, b.index)
+	})
+	var dominant []structField
+	for i := 0; i < len(fields); {
+		j := i + 1
+		for j < len(fields) && fields[j].name == fields[i].name {
+			j++
+		}
+		if f, ok := dominantField(fields[i:j]); ok {
+			dominant = append(dominant, f)
+		}
+		i = j
+	}
+	slices.SortFunc(dominant, func(a, b structField) int {
+		return slices.Compare(a.index, b.index)
+	})
+	return dominant
+}
+
+// dominantField returns the field which wins among the fields sharing a name, sorted by depth and tagged.
+func dominantField(fields []structField) (structField, bool) {
+	if len(fields) > 1 && fields[0].depth == fields[1].depth && fields[0].tagged == fields[1].tagged {
+		return structField{}, false
+	}
+	return fields[0], true
+}
+
+func (structuredCodec) Decode(v js.Value, ptr any) error {
+	if v.IsUndefined() {
+		return ErrUndefined
+	}
+	rv := reflect.ValueOf(ptr)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("codec: Decode requires a non-nil pointer")
+	}
+	return decodeValue(v, rv.Elem())
+}
+
+func isBinary(v js.Value) bool {
+	return v.InstanceOf(arrayBufferClass) || arrayBufferClass.Call("isView", v).Bool()
+}
+
+func toBytes(v js.Value) []byte {
+	var ua js.Value
+	if v.InstanceOf(arrayBufferClass) {
+		ua = jsutil.Uint8ArrayClass.New(v)
+	} else {
+		ua = jsutil.Uint8ArrayClass.New(v.Get("buffer"), v.Get("byteOffset"), v.Get("byteLength"))
+	}
+	b := make([]byte, ua.Length())
+	js.CopyBytesToGo(b, ua)
+	return b
+}
+
+func decodeValue(v js.Value, rv reflect.Value) error {
+	if rv.Type() == jsValueType {
+		rv.Set(reflect.ValueOf(v))
+		return nil
+	}
+	if !isKnownType(v) {
+		return errUnknownType
+	}
+	if v.IsNull() || v.IsUndefined() {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+	if rv.Type() == timeType {
+		if !v.InstanceOf(jsutil.DateClass) {
+			return fmt.Errorf("codec: cannot decode %s into time.Time", v.Type())
+		}
+		t, err := jsutil.DateToTime(v)
+		if err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(t))
+		return nil
+	}
+	switch rv.Kind() {
+	case reflect.Interface:
+		if rv.NumMethod() != 0 {
+			return fmt.Errorf("codec: cannot decode into non-empty interface %s", rv.Type())
+		}
+		x, err := decodeAny(v)
+		if err != nil {
+			return err
+		}
+		if x != nil {
+			rv.Set(reflect.ValueOf(x))
+		}
+		return nil
+	case reflect.Pointer:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return decodeValue(v, rv.Elem())
+	case reflect.Bool:
+		if v.Type() != js.TypeBoolean {
+			return decodeTypeError(v, rv)
+		}
+		rv.SetBool(v.Bool())
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() != js.TypeNumber {
+			return decodeTypeError(v, rv)
+		}
+		rv.SetInt(int64(v.Float()))
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Type() != js.TypeNumber {
+			return decodeTypeError(v, rv)
+		}
+		rv.SetUint(uint64(v.Float()))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		if v.Type() != js.TypeNumber {
+			return decodeTypeError(v, rv)
+		}
+		rv.SetFloat(v.Float())
+		return nil
+	case reflect.String:
+		if v.Type() != js.TypeString {
+			return decodeTypeError(v, rv)
+		}
+		rv.SetString(v.String())
+		return nil
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 && isBinary(v) {
+			rv.SetBytes(toBytes(v))
+			return nil
+		}
+		if !jsutil.ArrayClass.Call("isArray", v).Bool() {
+			return decodeTypeError(v, rv)
+		}
+		s := reflect.MakeSlice(rv.Type(), v.Length(), v.Length())
+		for i := 0; i < v.Length(); i++ {
+			if err := decodeValue(v.Index(i), s.Index(i)); err != nil {
+				return err
+			}
+		}
+		rv.Set(s)
+		return nil
+	case reflect.Array:
+		if !jsutil.ArrayClass.Call("isArray", v).Bool() {
+			return decodeTypeError(v, rv)
+		}
+		for i := 0; i < rv.Len() && i < v.Length(); i++ {
+			if err := decodeValue(v.Index(i), rv.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String || v.Type() != js.TypeObject {
+			return decodeTypeError(v, rv)
+		}
+		m := reflect.MakeMap(rv.Type())
+		err := forEachEntry(v, func(key string, value js.Value) error {
+			ev := reflect.New(rv.Type().Elem()).Elem()
+			if err := decodeValue(value, ev); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(key).Convert(rv.Type().Key()), ev)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		rv.Set(m)
+		return nil
+	case reflect.Struct:
+		if v.Type() != js.TypeObject {
+			return decodeTypeError(v, rv)
+		}
+		for _, f := range structFields(rv.Type()) {
+			fv := v.Get(f.name)
+			if fv.IsUndefined() {
+				continue
+			}
+			field, err := fieldByIndexAlloc(rv, f.index)
+			if err != nil {
+				return err
+			}
+			if err := decodeValue(fv, field); err != nil {
+				return fmt.Errorf("codec: field %s: %w", f.name, err)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("codec: unsupported type: %s", rv.Type())
+}
+
+// fieldByIndexAlloc returns the field of the index allocating nil embedded struct pointers.
+func fieldByIndexAlloc(rv reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				if !rv.CanSet() {
+					return reflect.Value{}, fmt.Errorf("codec: cannot set embedded pointer to unexported struct: %s", rv.Type().Elem())
+				}
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, nil
+}
+
+func decodeTypeError(v js.Value, rv reflect.Value) error {
+	return fmt.Errorf("codec: cannot decode %s into %s", v.Type(), rv.Type())
+}
+
+// forEachEntry calls fn for each entry of a Map or the own enumerable properties of an object.
+func forEachEntry(v js.Value, fn func(key string, value js.Value) error) error {
+	var entries js.Value
+	if v.InstanceOf(mapClass) {
+		entries = jsutil.ArrayClass.Call("from", v.Call("entries"))
+	} else {
+		entries = jsutil.ObjectClass.Call("entries", v)
+	}
+	for i := 0; i < entries.Length(); i++ {
+		entry := entries.Index(i)
+		key := entry.Index(0)
+		if key.Type() != js.TypeString {
+			return fmt.Errorf("codec: unsupported map key type: %s", key.Type())
+		}
+		if err := fn(key.String(), entry.Index(1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// errUnknownType is returned when a JS value has a type unknown to syscall/js (e.g. bigint).
+var errUnknownType = errors.New("codec: unsupported JS type")
+
+// isKnownType reports whether the type of v is known to syscall/js.
+// js.Value.Type panics for the other types such as bigint.
+func isKnownType(v js.Value) (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	v.Type()
+	return true
+}
+
+// decodeAny converts a JS value into a Go value for `any`.
+func decodeAny(v js.Value) (any, error) {
+	if !isKnownType(v) {
+		return nil, errUnknownType
+	}
+	switch v.Type() {
+	case js.TypeNull, js.TypeUndefined:
+		return nil, nil
+	case js.TypeBoolean:
+		return v.Bool(), nil
+	case js.TypeNumber:
+		return v.Float(), nil
+	case js.TypeString:
+		return v.String(), nil
+	case js.TypeObject:
+		switch {
+		case v.InstanceOf(jsutil.DateClass):
+			return jsutil.DateToTime(v)
+		case isBinary(v):
+			return toBytes(v), nil
+		case jsutil.ArrayClass.Call("isArray", v).Bool():
+			s := make([]any, v.Length())
+			for i := range s {
+				x, err := decodeAny(v.Index(i))
+				if err != nil {
+					return nil, err
+				}
+				s[i] = x
+			}
+			return s, nil
+		}
+		m := map[string]any{}
+		err := forEachEntry(v, func(key string, value js.Value) error {
+			x, err := decodeAny(value)
+			if err != nil {
+				return err
+			}
+			m[key] = x
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("codec: unsupported JS type: %s", v.Type())
+}
@@ -0,0 +1,218 @@
+//go:build js && wasm
+
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"syscall/js"
+	"testing"
+	"time"
+)
+
+type embedded struct {
+	Region string `json:"region"`
+}
+
+type user struct {
+	embedded
+	ID       int64             `json:"id"`
+	Name     string            `json:"name"`
+	Avatar   []byte            `json:"avatar"`
+	JoinedAt time.Time         `json:"joinedAt"`
+	Tags     []string          `json:"tags,omitempty"`
+	Labels   map[string]string `json:"labels"`
+	Manager  *user             `json:"manager"`
+	Secret   string            `json:"-"`
+	Score    float64
+}
+
+func TestStructuredClone_RoundTrip(t *testing.T) {
+	want := user{
+		embedded: embedded{Region: "eu"},
+		ID:       42,
+		Name:     "alice",
+		Avatar:   []byte{0, 1, 2},
+		JoinedAt: time.UnixMilli(1700000000000),
+		Labels:   map[string]string{"team": "platform"},
+		Manager:  &user{ID: 1, Name: "bob", JoinedAt: time.UnixMilli(1600000000000)},
+		Secret:   "hidden",
+		Score:    1.5,
+	}
+	v, err := StructuredClone.Encode(want)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if !v.Get("avatar").InstanceOf(js.Global().Get("Uint8Array")) {
+		t.Error("[]byte must be encoded into Uint8Array")
+	}
+	if !v.Get("joinedAt").InstanceOf(js.Global().Get("Date")) {
+		t.Error("time.Time must be encoded into Date")
+	}
+	if got := v.Get("region").String(); got != "eu" {
+		t.Errorf("region = %q, want %q", got, "eu")
+	}
+	if !v.Get("tags").IsUndefined() {
+		t.Error("empty tags must be omitted")
+	}
+	if !v.Get("Secret").IsUndefined() {
+		t.Error("ignored field must not be encoded")
+	}
+
+	// structuredClone ensures the encoded value can be sent over RPC.
+	cloned := js.Global().Call("structuredClone", v)
+	var got user
+	if err := StructuredClone.Decode(cloned, &got); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	want.Secret = ""
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode() = %+v, want %+v", got, want)
+	}
+}
+
+func TestStructuredClone_DecodeAny(t *testing.T) {
+	v, err := StructuredClone.Encode(map[string]any{
+		"n":    1,
+		"list": []any{"a", true, nil},
+		"bin":  []byte("hi"),
+	})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	var got any
+	if err := StructuredClone.Decode(v, &got); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	want := map[string]any{
+		"n":    float64(1),
+		"list": []any{"a", true, nil},
+		"bin":  []byte("hi"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode() = %#v, want %#v", got, want)
+	}
+}
+
+func TestStructuredClone_DecodeBigInt(t *testing.T) {
+	big := js.Global().Get("BigInt").Invoke(10)
+	var got any
+	if err := StructuredClone.Decode(big, &got); err == nil {
+		t.Error("Decode() into any must return an error for bigint")
+	}
+	var n int
+	if err := StructuredClone.Decode(big, &n); err == nil {
+		t.Error("Decode() into int must return an error for bigint")
+	}
+	var m map[string]any
+	if err := StructuredClone.Decode(js.Global().Get("Object").Call("fromEntries", []any{[]any{"n", big}}), &m); err == nil {
+		t.Error("Decode() must return an error for nested bigint")
+	}
+}
+
+func TestStructuredClone_DecodeTypeError(t *testing.T) {
+	var n int
+	if err := StructuredClone.Decode(js.ValueOf("not a number"), &n); err == nil {
+		t.Error("Decode() must return an error for a mismatched type")
+	}
+}
+
+type withEmbeddedPointer struct {
+	*embedded
+	Name string `json:"name"`
+}
+
+func TestStructuredClone_EncodeNilEmbeddedPointer(t *testing.T) {
+	v, err := StructuredClone.Encode(withEmbeddedPointer{Name: "alice"})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if !v.Get("region").IsUndefined() {
+		t.Error("field of nil embedded pointer must be skipped")
+	}
+	if got := v.Get("name").String(); got != "alice" {
+		t.Errorf("name = %q, want %q", got, "alice")
+	}
+}
+
+type shadowInner struct {
+	Name   string
+	Tagged string
+	Ambig  string
+	Deep   string
+}
+
+type shadowTagged struct {
+	Other string `json:"Tagged"`
+	Ambig string
+}
+
+type shadowMiddle struct {
+	shadowInner
+}
+
+type shadowOuter struct {
+	shadowMiddle
+	shadowTagged
+	Name string
+}
+
+func TestStructuredClone_EncodeShadowedFields(t *testing.T) {
+	in := shadowOuter{
+		shadowMiddle: shadowMiddle{shadowInner{Name: "inner", Tagged: "inner", Ambig: "inner", Deep: "deep"}},
+		shadowTagged: shadowTagged{Other: "tagged", Ambig: "tagged"},
+		Name:         "outer",
+	}
+	v, err := StructuredClone.Encode(in)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	got := js.Global().Get("JSON").Call("stringify", v).String()
+	want, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("Encode() = %s, want %s", got, want)
+	}
+}
+
+type node struct {
+	Name string `json:"name"`
+	Next *node  `json:"next"`
+}
+
+func TestStructuredClone_EncodeCycle(t *testing.T) {
+	self := &node{Name: "self"}
+	self.Next = self
+
+	m := map[string]any{}
+	m["self"] = m
+
+	s := []any{nil}
+	s[0] = s
+
+	for name, v := range map[string]any{
+		"pointer": self,
+		"map":     m,
+		"slice":   s,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := StructuredClone.Encode(v); !errors.Is(err, ErrCycle) {
+				t.Errorf("Encode() error = %v, want %v", err, ErrCycle)
+			}
+		})
+	}
+}
+
+func TestStructuredClone_EncodeSharedPointer(t *testing.T) {
+	shared := &node{Name: "shared"}
+	v, err := StructuredClone.Encode([]*node{shared, shared, {Name: "head", Next: shared}})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if got := v.Index(2).Get("next").Get("name").String(); got != "shared" {
+		t.Errorf("name = %q, want %q", got, "shared")
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !js
+
+package codec
+
+import "errors"
+
+// Value represents a JS value which is decoded by a Codec.
+// This type is implemented for non-JS environments so that the packages referring to it can be built,
+// and it never holds any value.
+type Value struct{}
+
+// Decode always returns an error in non-JS environments.
+func (v *Value) Decode(any) error {
+	return errors.New("codec: Value is not supported in non-JS environments")
+}
+
+// IsUndefined always returns true in non-JS environments.
+func (v *Value) IsUndefined() bool {
+	return true
+}
@@ -0,0 +1,28 @@
+//go:build js && wasm
+
+package codec
+
+import (
+	"syscall/js"
+)
+
+// Value represents a JS value which is decoded by a Codec.
+type Value struct {
+	value js.Value
+	codec Codec
+}
+
+// NewValue returns Value which decodes v by c.
+func NewValue(v js.Value, c Codec) *Value {
+	return &Value{value: v, codec: c}
+}
+
+// Decode decodes the value into the value pointed to by ptr.
+func (v *Value) Decode(ptr any) error {
+	return v.codec.Decode(v.value, ptr)
+}
+
+// IsUndefined reports whether the value is undefined.
+func (v *Value) IsUndefined() bool {
+	return v.value.IsUndefined()
+}
@@ -3,13 +3,17 @@ package cron
 import (
 	"context"
 	"errors"
+	"syscall/js"
 	"time"
 
 	"github.com/syumai/workers/internal/runtimecontext"
 )
 
 // Event represents information about the Cron that invoked this worker.
+// In Cloudflare API documentation, this object represents the ScheduledController.
+//   - https://developers.cloudflare.com/workers/runtime-apis/handlers/scheduled/#controller
 type Event struct {
+	instance      js.Value
 	Cron          string
 	ScheduledTime time.Time
 }
@@ -22,7 +26,13 @@ func NewEvent(ctx context.Context) (*Event, error) {
 
 	scheduledTimeVal := obj.Get("scheduledTime").Float()
 	return &Event{
+		instance:      obj,
 		Cron:          obj.Get("cron").String(),
 		ScheduledTime: time.Unix(int64(scheduledTimeVal)/1000, 0).UTC(),
 	}, nil
 }
+
+// NoRetry prevents the runtime from retrying this event when the task fails.
+func (e *Event) NoRetry() {
+	e.instance.Call("noRetry")
+}
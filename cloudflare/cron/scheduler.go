@@ -3,10 +3,13 @@ package cron
 import (
 	"context"
 	"fmt"
+	"runtime/debug"
+	"sync"
 	"syscall/js"
 
 	"github.com/syumai/workers"
 	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/recovery"
 	"github.com/syumai/workers/internal/runtimecontext"
 )
 
@@ -15,11 +18,51 @@ type Task func(ctx context.Context) error
 var (
 	scheduledTask Task
 	doneCh        = make(chan struct{})
+
+	tasksMu sync.RWMutex
+	tasks   = map[string]Task{}
 )
 
-func runScheduler(eventObj js.Value) error {
-	ctx := runtimecontext.New(context.Background(), eventObj)
-	if err := scheduledTask(ctx); err != nil {
+// Handle registers the Task to be executed when the worker is invoked by the given Cron Trigger.
+// cron must be the same string as the one defined in the `triggers.crons` of wrangler.toml.
+// Events of Cron Triggers without a registered Task are handled by the Task given to ScheduleTask.
+// Handle should be called before ScheduleTask or other blocking handlers (e.g. workers.Serve).
+//   - https://developers.cloudflare.com/workers/configuration/cron-triggers/
+func Handle(cron string, task Task) {
+	tasksMu.Lock()
+	defer tasksMu.Unlock()
+	tasks[cron] = task
+}
+
+// lookupTask returns the Task registered for cron, or the fallback Task set by ScheduleTask.
+func lookupTask(cron string) (Task, error) {
+	tasksMu.RLock()
+	task, ok := tasks[cron]
+	tasksMu.RUnlock()
+	if ok {
+		return task, nil
+	}
+	if scheduledTask == nil {
+		return nil, fmt.Errorf("task for cron %q is not registered", cron)
+	}
+	return scheduledTask, nil
+}
+
+// runScheduler runs the scheduled task.
+// A panic in the task is recovered, reported and returned as an error.
+func runScheduler(eventObj, runtimeObj js.Value) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			recovery.Report(nil, v, debug.Stack())
+			err = recovery.Error(v)
+		}
+	}()
+	task, err := lookupTask(eventObj.Get("cron").String())
+	if err != nil {
+		return err
+	}
+	ctx := runtimecontext.New(context.Background(), eventObj, runtimeObj)
+	if err := task(ctx); err != nil {
 		return err
 	}
 	return nil
@@ -27,18 +70,24 @@ func runScheduler(eventObj js.Value) error {
 
 func init() {
 	runSchedulerCallback := js.FuncOf(func(_ js.Value, args []js.Value) any {
-		if len(args) != 1 {
+		if len(args) != 1 && len(args) != 2 {
 			panic(fmt.Errorf("invalid number of arguments given to runScheduler: %d", len(args)))
 		}
 		eventObj := args[0]
+		runtimeObj := jsutil.RuntimeContext
+		if len(args) > 1 {
+			runtimeObj = args[1]
+		}
 		var cb js.Func
 		cb = js.FuncOf(func(_ js.Value, pArgs []js.Value) any {
 			defer cb.Release()
 			resolve := pArgs[0]
+			reject := pArgs[1]
 			go func() {
-				err := runScheduler(eventObj)
+				err := runScheduler(eventObj, runtimeObj)
 				if err != nil {
-					panic(err)
+					reject.Invoke(jsutil.Error(err.Error()))
+					return
 				}
 				resolve.Invoke(js.Undefined())
 			}()
@@ -50,14 +99,15 @@ func init() {
 	jsutil.Binding.Set("runScheduler", runSchedulerCallback)
 }
 
-// ScheduleTask sets the Task to be executed
+// ScheduleTask sets the Task to be executed.
+// When Tasks are registered by Handle, the Task is used as a fallback for the other Cron Triggers and can be nil.
 func ScheduleTask(task Task) {
 	scheduledTask = task
 	workers.Ready()
 	<-Done()
 }
 
-// ScheduleTaskNonBlock sets the Task to be executed but does not signal readiness or block
+// ScheduleTaskNonBlock sets the Task to be executed (or the fallback Task as ScheduleTask does) but does not signal readiness or block
 // indefinitely. The non-blocking form is meant to be used in conjunction with [workers.Serve].
 func ScheduleTaskNonBlock(task Task) {
 	scheduledTask = task
@@ -0,0 +1,110 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/recovery"
+)
+
+func newController(t *testing.T, cron string) (obj js.Value, noRetryCalled *bool) {
+	t.Helper()
+	called := false
+	noRetry := js.FuncOf(func(js.Value, []js.Value) any {
+		called = true
+		return js.Undefined()
+	})
+	t.Cleanup(noRetry.Release)
+	obj = jsutil.NewObject()
+	obj.Set("cron", cron)
+	obj.Set("scheduledTime", time.Now().UnixMilli())
+	obj.Set("noRetry", noRetry)
+	return obj, &called
+}
+
+func resetTasks(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		tasks = map[string]Task{}
+		scheduledTask = nil
+	})
+}
+
+func TestRunScheduler_Handle(t *testing.T) {
+	resetTasks(t)
+	var got []string
+	Handle("*/5 * * * *", func(ctx context.Context) error {
+		got = append(got, "five")
+		return nil
+	})
+	ScheduleTaskNonBlock(func(ctx context.Context) error {
+		e, err := NewEvent(ctx)
+		if err != nil {
+			return err
+		}
+		got = append(got, "fallback:"+e.Cron)
+		return nil
+	})
+	for _, cron := range []string{"*/5 * * * *", "0 0 * * *"} {
+		obj, _ := newController(t, cron)
+		if err := runScheduler(obj, jsutil.NewObject()); err != nil {
+			t.Fatalf("runScheduler(%q) failed: %v", cron, err)
+		}
+	}
+	want := []string{"five", "fallback:0 0 * * *"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("tasks run = %v, want %v", got, want)
+	}
+}
+
+func TestRunScheduler_NotRegistered(t *testing.T) {
+	resetTasks(t)
+	Handle("*/5 * * * *", func(ctx context.Context) error { return nil })
+	obj, _ := newController(t, "0 0 * * *")
+	if err := runScheduler(obj, jsutil.NewObject()); err == nil {
+		t.Fatal("runScheduler() error = nil, want error")
+	}
+}
+
+func TestRunScheduler_Error(t *testing.T) {
+	resetTasks(t)
+	wantErr := errors.New("failed")
+	Handle("* * * * *", func(ctx context.Context) error {
+		e, err := NewEvent(ctx)
+		if err != nil {
+			return err
+		}
+		e.NoRetry()
+		return wantErr
+	})
+	obj, noRetryCalled := newController(t, "* * * * *")
+	if err := runScheduler(obj, jsutil.NewObject()); !errors.Is(err, wantErr) {
+		t.Fatalf("runScheduler() error = %v, want %v", err, wantErr)
+	}
+	if !*noRetryCalled {
+		t.Error("noRetry was not called")
+	}
+}
+
+func TestRunScheduler_Panic(t *testing.T) {
+	resetTasks(t)
+	var reported any
+	recovery.SetHook(func(_ *http.Request, v any, _ []byte) { reported = v })
+	t.Cleanup(func() { recovery.SetHook(nil) })
+
+	Handle("* * * * *", func(ctx context.Context) error {
+		panic("boom")
+	})
+	obj, _ := newController(t, "* * * * *")
+	if err := runScheduler(obj, jsutil.NewObject()); err == nil || err.Error() != "panic: boom" {
+		t.Fatalf("runScheduler() error = %v, want panic: boom", err)
+	}
+	if reported != "boom" {
+		t.Errorf("reported = %v, want %v", reported, "boom")
+	}
+}
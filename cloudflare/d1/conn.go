@@ -24,7 +24,10 @@ func (c *Conn) Prepare(query string) (driver.Stmt, error) {
 	}, nil
 }
 
-func (c *Conn) PrepareContext(_ context.Context, query string) (driver.Stmt, error) {
+func (c *Conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	return c.Prepare(query)
 }
 
@@ -18,8 +18,15 @@ var (
 
 // OpenConnector returns Connector of D1.
 // This method checks DB existence. If DB was not found, this function returns error.
+//   - On an instance shared across events (workers-assets-gen -instance=shared), this function fails since the event is unknown. Use OpenConnectorFromContext instead.
 func OpenConnector(name string) (driver.Connector, error) {
-	v := cfruntimecontext.MustGetRuntimeContextEnv().Get(name)
+	return OpenConnectorFromContext(context.Background(), name)
+}
+
+// OpenConnectorFromContext returns Connector of D1 resolved from the runtime context attached to ctx.
+// If the runtime context is not attached to ctx, this function behaves the same as OpenConnector.
+func OpenConnectorFromContext(ctx context.Context, name string) (driver.Connector, error) {
+	v := cfruntimecontext.MustGetRuntimeContextEnvFromContext(ctx).Get(name)
 	if v.IsUndefined() {
 		return nil, ErrDatabaseNotFound
 	}
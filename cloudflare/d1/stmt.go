@@ -35,7 +35,8 @@ func (s *stmt) Exec([]driver.Value) (driver.Result, error) {
 
 // ExecContext executes prepared statement.
 // Given []driver.NamedValue's `Name` field will be ignored because Cloudflare D1 client doesn't support it.
-func (s *stmt) ExecContext(_ context.Context, args []driver.NamedValue) (driver.Result, error) {
+// If ctx is done before the statement completes, ctx.Err() is returned. The statement itself is not cancelled.
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
 	argValues := make([]any, len(args))
 	for i, arg := range args {
 		if src, ok := arg.Value.([]byte); ok {
@@ -49,7 +50,7 @@ func (s *stmt) ExecContext(_ context.Context, args []driver.NamedValue) (driver.
 		}
 	}
 	resultPromise := s.stmtObj.Call("bind", argValues...).Call("run")
-	resultObj, err := jsutil.AwaitPromise(resultPromise)
+	resultObj, err := jsutil.AwaitPromiseContext(ctx, resultPromise)
 	if err != nil {
 		return nil, err
 	}
@@ -62,7 +63,9 @@ func (s *stmt) Query([]driver.Value) (driver.Rows, error) {
 	return nil, errors.New("d1: Query is deprecated and not implemented")
 }
 
-func (s *stmt) QueryContext(_ context.Context, args []driver.NamedValue) (driver.Rows, error) {
+// QueryContext executes prepared statement and returns its rows.
+// If ctx is done before the statement completes, ctx.Err() is returned. The statement itself is not cancelled.
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
 	argValues := make([]any, len(args))
 	for i, arg := range args {
 		if src, ok := arg.Value.([]byte); ok {
@@ -76,7 +79,7 @@ func (s *stmt) QueryContext(_ context.Context, args []driver.NamedValue) (driver
 		}
 	}
 	resultPromise := s.stmtObj.Call("bind", argValues...).Call("raw", map[string]any{"columnNames": true})
-	rowsArray, err := jsutil.AwaitPromise(resultPromise)
+	rowsArray, err := jsutil.AwaitPromiseContext(ctx, resultPromise)
 	if err != nil {
 		return nil, err
 	}
@@ -1,10 +1,12 @@
 package cloudflare
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"syscall/js"
 
+	"github.com/syumai/workers/cloudflare/codec"
 	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
 	"github.com/syumai/workers/internal/jshttp"
 	"github.com/syumai/workers/internal/jsutil"
@@ -19,8 +21,18 @@ type DurableObjectNamespace struct {
 //
 // This binding must be defined in the `wrangler.toml` file. The method will
 // return an `error` when there is no binding defined by `varName`.
+//   - On an instance shared across events (workers-assets-gen -instance=shared), this function fails since the event is unknown. Use NewDurableObjectNamespaceFromContext instead.
 func NewDurableObjectNamespace(varName string) (*DurableObjectNamespace, error) {
-	inst := cfruntimecontext.MustGetRuntimeContextEnv().Get(varName)
+	return NewDurableObjectNamespaceFromContext(context.Background(), varName)
+}
+
+// NewDurableObjectNamespaceFromContext returns the namespace for the `varName` binding
+// resolved from the runtime context attached to ctx.
+//
+// If the runtime context is not attached to ctx, this function behaves the same as
+// NewDurableObjectNamespace.
+func NewDurableObjectNamespaceFromContext(ctx context.Context, varName string) (*DurableObjectNamespace, error) {
+	inst := cfruntimecontext.MustGetRuntimeContextEnvFromContext(ctx).Get(varName)
 	if inst.IsUndefined() {
 		return nil, fmt.Errorf("%s is undefined", varName)
 	}
@@ -35,14 +47,99 @@ func (ns *DurableObjectNamespace) IdFromName(name string) *DurableObjectId {
 	return &DurableObjectId{val: id}
 }
 
+// NewUniqueIdOptions represents the options of NewUniqueId.
+type NewUniqueIdOptions struct {
+	// Jurisdiction restricts the durable object to the jurisdiction (e.g. "eu", "fedramp").
+	Jurisdiction string
+}
+
+func (opts *NewUniqueIdOptions) toJS() js.Value {
+	if opts == nil {
+		return js.Undefined()
+	}
+	obj := jsutil.NewObject()
+	if opts.Jurisdiction != "" {
+		obj.Set("jurisdiction", opts.Jurisdiction)
+	}
+	return obj
+}
+
+// NewUniqueId returns a new random `DurableObjectId`.
+//
+// https://developers.cloudflare.com/durable-objects/api/namespace/#newuniqueid
+func (ns *DurableObjectNamespace) NewUniqueId(opts *NewUniqueIdOptions) *DurableObjectId {
+	id := ns.instance.Call("newUniqueId", opts.toJS())
+	return &DurableObjectId{val: id}
+}
+
+// IdFromString returns the `DurableObjectId` for the string created by `DurableObjectId.String`.
+// The method returns an `error` when the string is not a valid ID of this namespace.
+//
+// https://developers.cloudflare.com/durable-objects/api/namespace/#idfromstring
+func (ns *DurableObjectNamespace) IdFromString(id string) (*DurableObjectId, error) {
+	v, err := jsutil.TryCatchCall(ns.instance, "idFromString", id)
+	if err != nil {
+		return nil, err
+	}
+	return &DurableObjectId{val: v}, nil
+}
+
+// Jurisdiction returns the sub-namespace whose IDs are restricted to the jurisdiction (e.g. "eu", "fedramp").
+//
+// https://developers.cloudflare.com/durable-objects/api/namespace/#jurisdiction
+func (ns *DurableObjectNamespace) Jurisdiction(jurisdiction string) (*DurableObjectNamespace, error) {
+	v, err := jsutil.TryCatchCall(ns.instance, "jurisdiction", jurisdiction)
+	if err != nil {
+		return nil, err
+	}
+	return &DurableObjectNamespace{instance: v}, nil
+}
+
+// DurableObjectGetOptions represents the options to obtain a durable object stub.
+type DurableObjectGetOptions struct {
+	// LocationHint is a hint of the location where the durable object is created (e.g. "wnam", "weur").
+	// This is used only when the durable object is created for the first time.
+	LocationHint string
+}
+
+func (opts *DurableObjectGetOptions) toJS() js.Value {
+	if opts == nil {
+		return js.Undefined()
+	}
+	obj := jsutil.NewObject()
+	if opts.LocationHint != "" {
+		obj.Set("locationHint", opts.LocationHint)
+	}
+	return obj
+}
+
 // Get obtains the durable object stub for `id`.
 //
 // https://developers.cloudflare.com/workers/runtime-apis/durable-objects/#obtaining-an-object-stub
 func (ns *DurableObjectNamespace) Get(id *DurableObjectId) (*DurableObjectStub, error) {
+	return ns.GetWithOptions(id, nil)
+}
+
+// GetWithOptions obtains the durable object stub for `id` with the given options.
+//
+// https://developers.cloudflare.com/durable-objects/api/namespace/#get
+func (ns *DurableObjectNamespace) GetWithOptions(id *DurableObjectId, opts *DurableObjectGetOptions) (*DurableObjectStub, error) {
 	if id == nil || id.val.IsUndefined() {
 		return nil, fmt.Errorf("invalid UniqueGlobalId")
 	}
-	stub := ns.instance.Call("get", id.val)
+	stub := ns.instance.Call("get", id.val, opts.toJS())
+	return &DurableObjectStub{val: stub}, nil
+}
+
+// GetByName obtains the durable object stub for the ID derived from `name`.
+// This is a shorthand of `IdFromName` and `GetWithOptions`.
+//
+// https://developers.cloudflare.com/durable-objects/api/namespace/#getbyname
+func (ns *DurableObjectNamespace) GetByName(name string, opts *DurableObjectGetOptions) (*DurableObjectStub, error) {
+	if ns.instance.Get("getByName").IsUndefined() {
+		return ns.GetWithOptions(ns.IdFromName(name), opts)
+	}
+	stub := ns.instance.Call("getByName", name, opts.toJS())
 	return &DurableObjectStub{val: stub}, nil
 }
 
@@ -51,6 +148,30 @@ type DurableObjectId struct {
 	val js.Value
 }
 
+// String returns the hex string of the ID.
+// The ID can be restored from the string by `DurableObjectNamespace.IdFromString`.
+func (id *DurableObjectId) String() string {
+	return id.val.Call("toString").String()
+}
+
+// Equals reports whether the ID is equal to `other`.
+func (id *DurableObjectId) Equals(other *DurableObjectId) bool {
+	if other == nil {
+		return false
+	}
+	return id.val.Call("equals", other.val).Bool()
+}
+
+// Name returns the name which the ID was derived from by `IdFromName`.
+// If the ID was not derived from a name, an empty string is returned.
+func (id *DurableObjectId) Name() string {
+	name := id.val.Get("name")
+	if name.IsUndefined() || name.IsNull() {
+		return ""
+	}
+	return name.String()
+}
+
 // DurableObjectStub represents the stub to communicate with the durable object.
 type DurableObjectStub struct {
 	val js.Value
@@ -70,3 +191,12 @@ func (s *DurableObjectStub) Fetch(req *http.Request) (*http.Response, error) {
 
 	return jshttp.ToResponse(jsRes)
 }
+
+// Call calls the RPC method of the durable object.
+//   - args are encoded by codec.StructuredClone, and the result can be decoded by Decode of the returned value.
+//   - If ctx is done before the call completes, ctx.Err() is returned. The call itself is not cancelled.
+//
+// https://developers.cloudflare.com/durable-objects/best-practices/create-durable-object-stubs-and-send-requests/#invoke-rpc-methods
+func (s *DurableObjectStub) Call(ctx context.Context, method string, args ...any) (*codec.Value, error) {
+	return callRPC(ctx, s.val, method, args)
+}
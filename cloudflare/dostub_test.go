@@ -0,0 +1,96 @@
+package cloudflare
+
+import (
+	"os"
+	"syscall/js"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// tryCatch is provided by instance.mjs on the runtime.
+	js.Global().Set("tryCatch", js.Global().Get("Function").New("fn", `
+		try {
+			return { result: fn() };
+		} catch (e) {
+			return { error: e };
+		}
+	`))
+	os.Exit(m.Run())
+}
+
+// newFakeNamespace returns a minimal implementation of DurableObjectNamespace.
+var newFakeNamespace = js.Global().Get("Function").New(`
+	const newId = (hex, name) => ({
+		name,
+		toString: () => hex,
+		equals: (other) => other.toString() === hex,
+	});
+	const ns = (jurisdiction) => ({
+		newUniqueId: (opts) => newId((opts && opts.jurisdiction ? opts.jurisdiction + "-" : "") + "unique"),
+		idFromName: (name) => newId((jurisdiction ? jurisdiction + "-" : "") + "named-" + name, name),
+		idFromString: (hex) => {
+			if (!/^[a-z0-9-]+$/.test(hex)) {
+				throw new TypeError("Invalid Durable Object ID");
+			}
+			return newId(hex);
+		},
+		jurisdiction: (j) => ns(j),
+		get: (id, opts) => ({ id, locationHint: opts && opts.locationHint }),
+	});
+	return ns();
+`)
+
+func TestDurableObjectNamespace_Ids(t *testing.T) {
+	ns := &DurableObjectNamespace{instance: newFakeNamespace.Invoke()}
+
+	unique := ns.NewUniqueId(&NewUniqueIdOptions{Jurisdiction: "eu"})
+	if got := unique.String(); got != "eu-unique" {
+		t.Errorf("NewUniqueId().String() = %q, want %q", got, "eu-unique")
+	}
+	if got := unique.Name(); got != "" {
+		t.Errorf("NewUniqueId().Name() = %q, want empty", got)
+	}
+
+	named := ns.IdFromName("tenant")
+	if got := named.Name(); got != "tenant" {
+		t.Errorf("IdFromName().Name() = %q, want %q", got, "tenant")
+	}
+	restored, err := ns.IdFromString(named.String())
+	if err != nil {
+		t.Fatalf("IdFromString() error = %v", err)
+	}
+	if !restored.Equals(named) {
+		t.Error("restored ID must equal the original ID")
+	}
+	if restored.Equals(unique) {
+		t.Error("restored ID must not equal another ID")
+	}
+	if _, err := ns.IdFromString("INVALID!"); err == nil {
+		t.Error("IdFromString() must return an error for an invalid ID")
+	}
+
+	eu, err := ns.Jurisdiction("eu")
+	if err != nil {
+		t.Fatalf("Jurisdiction() error = %v", err)
+	}
+	if got := eu.IdFromName("tenant").String(); got != "eu-named-tenant" {
+		t.Errorf("Jurisdiction().IdFromName().String() = %q, want %q", got, "eu-named-tenant")
+	}
+}
+
+func TestDurableObjectNamespace_GetByName(t *testing.T) {
+	ns := &DurableObjectNamespace{instance: newFakeNamespace.Invoke()}
+	stub, err := ns.GetByName("tenant", &DurableObjectGetOptions{LocationHint: "weur"})
+	if err != nil {
+		t.Fatalf("GetByName() error = %v", err)
+	}
+	if got := stub.val.Get("locationHint").String(); got != "weur" {
+		t.Errorf("locationHint = %q, want %q", got, "weur")
+	}
+	if got := stub.val.Get("id").Get("name").String(); got != "tenant" {
+		t.Errorf("id.name = %q, want %q", got, "tenant")
+	}
+	if _, err := ns.Get(nil); err == nil {
+		t.Error("Get(nil) must return an error")
+	}
+}
@@ -0,0 +1,81 @@
+package durableobject
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"syscall/js"
+	"time"
+
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/recovery"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+// AlarmInfo represents the information of an alarm invocation.
+//   - https://developers.cloudflare.com/durable-objects/api/alarms/#alarm
+type AlarmInfo struct {
+	// RetryCount is the number of times the alarm has been retried.
+	RetryCount int
+	// IsRetry reports whether this invocation is a retry.
+	IsRetry bool
+}
+
+func toAlarmInfo(v js.Value) *AlarmInfo {
+	if v.IsUndefined() || v.IsNull() {
+		return &AlarmInfo{}
+	}
+	return &AlarmInfo{
+		RetryCount: jsutil.MaybeInt(v.Get("retryCount")),
+		IsRetry:    v.Get("isRetry").Truthy(),
+	}
+}
+
+// AlarmHandler is implemented by the handler of a Durable Object to handle alarms.
+//   - If Alarm returns an error, the alarm is retried by the runtime with exponential backoff.
+type AlarmHandler interface {
+	Alarm(ctx context.Context, info *AlarmInfo) error
+}
+
+// alarm runs the alarm handler of the object.
+// A panic in the handler is recovered, reported and returned as an error.
+func (o *object) alarm(infoObj, runtimeObj js.Value) (err error) {
+	h, ok := o.handler.(AlarmHandler)
+	if !ok {
+		return fmt.Errorf("durable object handler doesn't implement AlarmHandler")
+	}
+	defer func() {
+		if v := recover(); v != nil {
+			recovery.Report(nil, v, debug.Stack())
+			err = recovery.Error(v)
+		}
+	}()
+	ctx := runtimecontext.New(context.Background(), infoObj, runtimeObj)
+	return h.Alarm(ctx, toAlarmInfo(infoObj))
+}
+
+// GetAlarm returns the time the alarm is scheduled at.
+//   - if no alarm is set, returns false.
+func (s *Storage) GetAlarm(opts *GetOptions) (time.Time, bool, error) {
+	v, err := jsutil.AwaitPromise(s.instance.Call("getAlarm", opts.toJS()))
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if v.IsNull() || v.IsUndefined() {
+		return time.Time{}, false, nil
+	}
+	return time.UnixMilli(int64(v.Float())), true, nil
+}
+
+// SetAlarm schedules the alarm at t. The existing alarm is overridden.
+//   - if t is in the past, the alarm runs immediately.
+func (s *Storage) SetAlarm(t time.Time, opts *PutOptions) error {
+	_, err := jsutil.AwaitPromise(s.instance.Call("setAlarm", jsutil.TimeToDate(t), opts.toJS()))
+	return err
+}
+
+// DeleteAlarm deletes the alarm if one is set.
+func (s *Storage) DeleteAlarm(opts *PutOptions) error {
+	_, err := jsutil.AwaitPromise(s.instance.Call("deleteAlarm", opts.toJS()))
+	return err
+}
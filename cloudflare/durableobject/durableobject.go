@@ -0,0 +1,148 @@
+package durableobject
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"syscall/js"
+
+	"github.com/syumai/workers/internal/jshttp"
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/recovery"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+// Constructor creates the http.Handler of a Durable Object.
+//   - ctx holds the env of the Durable Object, so the bindings can be resolved by the FromContext functions of the cloudflare packages.
+//   - The constructor is called once for each instance of the Durable Object.
+//   - The handler may implement AlarmHandler to handle alarms.
+//   - The handler may implement WebSocketMessageHandler, WebSocketCloseHandler and WebSocketErrorHandler to handle hibernatable WebSockets.
+type Constructor func(ctx context.Context, state *State) http.Handler
+
+var (
+	constructorsMu sync.RWMutex
+	constructors   = map[string]Constructor{}
+)
+
+// Register registers the Constructor of the Durable Object class named className.
+//   - The class must be exported from worker.mjs by workers-assets-gen with the `-durable-objects` flag.
+//   - This function must be called before workers.Serve (or workers.Ready).
+func Register(className string, constructor Constructor) {
+	constructorsMu.Lock()
+	defer constructorsMu.Unlock()
+	constructors[className] = constructor
+}
+
+func getConstructor(className string) (Constructor, bool) {
+	constructorsMu.RLock()
+	defer constructorsMu.RUnlock()
+	c, ok := constructors[className]
+	return c, ok
+}
+
+// object is an instance of a Durable Object class.
+type object struct {
+	handler http.Handler
+}
+
+// newObject creates an instance of the Durable Object class named className.
+// A panic in the constructor is recovered, reported and returned as an error.
+func newObject(className string, stateObj, runtimeObj js.Value) (obj *object, err error) {
+	constructor, ok := getConstructor(className)
+	if !ok {
+		return nil, fmt.Errorf("durable object class is not registered: %s", className)
+	}
+	defer func() {
+		if v := recover(); v != nil {
+			recovery.Report(nil, v, debug.Stack())
+			err = recovery.Error(v)
+		}
+	}()
+	ctx := runtimecontext.WithRuntimeObj(context.Background(), runtimeObj)
+	handler := constructor(ctx, &State{instance: stateObj})
+	if handler == nil {
+		return nil, fmt.Errorf("constructor of %s returned nil handler", className)
+	}
+	return &object{handler: handler}, nil
+}
+
+// fetch serves a Request object sent to the Durable Object, and returns Response object.
+func (o *object) fetch(reqObj, runtimeObj js.Value) (js.Value, error) {
+	ctx := runtimecontext.New(context.Background(), reqObj, runtimeObj)
+	return jshttp.ServeRequest(ctx, o.handler, reqObj, jshttp.ServeOptions{})
+}
+
+// toJS converts the object into a JS object whose methods are called by the class shim.
+// The last argument of each method is the runtime context object of the event.
+func (o *object) toJS() js.Value {
+	obj := jsutil.NewObject()
+	setMethod(obj, "fetch", 2, func(args []js.Value) (js.Value, error) {
+		return o.fetch(args[0], args[1])
+	})
+	setMethod(obj, "alarm", 2, func(args []js.Value) (js.Value, error) {
+		return js.Undefined(), o.alarm(args[0], args[1])
+	})
+	setMethod(obj, "webSocketMessage", 3, func(args []js.Value) (js.Value, error) {
+		return js.Undefined(), o.webSocketMessage(args[0], args[1], args[2])
+	})
+	setMethod(obj, "webSocketClose", 5, func(args []js.Value) (js.Value, error) {
+		return js.Undefined(), o.webSocketClose(args[0], args[1].Int(), args[2].String(), args[3].Bool(), args[4])
+	})
+	setMethod(obj, "webSocketError", 3, func(args []js.Value) (js.Value, error) {
+		return js.Undefined(), o.webSocketError(args[0], args[1], args[2])
+	})
+	return obj
+}
+
+// setMethod sets the method which calls fn in a new goroutine and returns a Promise settled with its result.
+func setMethod(obj js.Value, name string, numArgs int, fn func(args []js.Value) (js.Value, error)) {
+	obj.Set(name, js.FuncOf(func(_ js.Value, args []js.Value) any {
+		if len(args) != numArgs {
+			panic(fmt.Errorf("invalid number of arguments given to %s: %d", name, len(args)))
+		}
+		return newPromise(func() (js.Value, error) {
+			return fn(args)
+		})
+	}))
+}
+
+// newPromise runs fn in a new goroutine, and returns a Promise settled with its result.
+func newPromise(fn func() (js.Value, error)) js.Value {
+	var cb js.Func
+	cb = js.FuncOf(func(_ js.Value, pArgs []js.Value) any {
+		defer cb.Release()
+		resolve := pArgs[0]
+		reject := pArgs[1]
+		go func() {
+			v, err := fn()
+			if err != nil {
+				reject.Invoke(jsutil.Error(err.Error()))
+				return
+			}
+			resolve.Invoke(v)
+		}()
+		return js.Undefined()
+	})
+	return jsutil.NewPromise(cb)
+}
+
+func init() {
+	newDurableObjectCallback := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		if len(args) != 3 {
+			panic(fmt.Errorf("invalid number of arguments given to newDurableObject: %d", len(args)))
+		}
+		className := args[0].String()
+		stateObj := args[1]
+		runtimeObj := args[2]
+		return newPromise(func() (js.Value, error) {
+			obj, err := newObject(className, stateObj, runtimeObj)
+			if err != nil {
+				return js.Value{}, err
+			}
+			return obj.toJS(), nil
+		})
+	})
+	jsutil.Binding.Set("newDurableObject", newDurableObjectCallback)
+}
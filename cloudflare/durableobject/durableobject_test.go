@@ -0,0 +1,137 @@
+package durableobject
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"syscall/js"
+	"testing"
+
+	"github.com/syumai/workers/cloudflare"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+func TestMain(m *testing.M) {
+	// tryCatch and callOrThrow are provided by instance.mjs on the runtime.
+	js.Global().Set("tryCatch", js.Global().Get("Function").New("fn", `
+		try {
+			return { result: fn() };
+		} catch (e) {
+			return { error: e };
+		}
+	`))
+	js.Global().Set("callOrThrow", js.Global().Get("Function").New("fn", `
+		const { result, error } = fn(...Array.prototype.slice.call(arguments, 1));
+		if (error !== undefined) {
+			throw error;
+		}
+		return result;
+	`))
+	os.Exit(m.Run())
+}
+
+func newFakeState(id string) js.Value {
+	idObj := jsutil.NewObject()
+	idObj.Set("toString", js.FuncOf(func(js.Value, []js.Value) any {
+		return id
+	}))
+	state := jsutil.NewObject()
+	state.Set("id", idObj)
+	return state
+}
+
+func newRuntimeObj(env js.Value, state js.Value) js.Value {
+	obj := jsutil.NewObject()
+	obj.Set("env", env)
+	obj.Set("ctx", state)
+	return obj
+}
+
+func TestNewObject(t *testing.T) {
+	Register("Counter", func(ctx context.Context, state *State) http.Handler {
+		greeting := cloudflare.GetenvContext(ctx, "GREETING")
+		var count int
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			count++
+			fmt.Fprintf(w, "%s %s %d %s", greeting, state.ID(), count, req.URL.Path)
+		})
+	})
+
+	env := jsutil.NewObject()
+	env.Set("GREETING", "hello")
+	state := newFakeState("abc")
+	obj, err := newObject("Counter", state, newRuntimeObj(env, state))
+	if err != nil {
+		t.Fatalf("newObject() error = %v", err)
+	}
+
+	for i, want := range []string{"hello abc 1 /increment", "hello abc 2 /increment"} {
+		reqObj := js.Global().Get("Request").New("https://example.com/increment")
+		resObj, err := obj.fetch(reqObj, newRuntimeObj(env, state))
+		if err != nil {
+			t.Fatalf("fetch() #%d error = %v", i, err)
+		}
+		text, err := jsutil.AwaitPromise(resObj.Call("text"))
+		if err != nil {
+			t.Fatalf("failed to read body #%d: %v", i, err)
+		}
+		if got := text.String(); got != want {
+			t.Errorf("body #%d = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestNewObject_Unregistered(t *testing.T) {
+	state := newFakeState("abc")
+	if _, err := newObject("Unknown", state, newRuntimeObj(jsutil.NewObject(), state)); err == nil {
+		t.Error("newObject() must return an error for an unregistered class")
+	}
+}
+
+func TestNewObject_ConstructorPanic(t *testing.T) {
+	Register("Broken", func(context.Context, *State) http.Handler {
+		panic("broken")
+	})
+	state := newFakeState("abc")
+	if _, err := newObject("Broken", state, newRuntimeObj(jsutil.NewObject(), state)); err == nil {
+		t.Error("newObject() must return an error when the constructor panics")
+	}
+}
+
+type alarmHandler struct {
+	http.Handler
+	infos []*AlarmInfo
+	err   error
+}
+
+func (h *alarmHandler) Alarm(_ context.Context, info *AlarmInfo) error {
+	h.infos = append(h.infos, info)
+	return h.err
+}
+
+func TestObject_Alarm(t *testing.T) {
+	h := &alarmHandler{Handler: http.NotFoundHandler()}
+	obj := &object{handler: h}
+	state := newFakeState("abc")
+
+	info := jsutil.NewObject()
+	info.Set("retryCount", 2)
+	info.Set("isRetry", true)
+	if err := obj.alarm(info, newRuntimeObj(jsutil.NewObject(), state)); err != nil {
+		t.Fatalf("alarm() error = %v", err)
+	}
+	if len(h.infos) != 1 || h.infos[0].RetryCount != 2 || !h.infos[0].IsRetry {
+		t.Errorf("infos = %+v, want [{RetryCount:2 IsRetry:true}]", h.infos)
+	}
+
+	h.err = fmt.Errorf("retry later")
+	if err := obj.alarm(js.Undefined(), newRuntimeObj(jsutil.NewObject(), state)); err != h.err {
+		t.Errorf("alarm() error = %v, want %v", err, h.err)
+	}
+
+	noAlarm := &object{handler: http.NotFoundHandler()}
+	if err := noAlarm.alarm(js.Undefined(), newRuntimeObj(jsutil.NewObject(), state)); err == nil {
+		t.Error("alarm() must return an error when the handler doesn't implement AlarmHandler")
+	}
+}
@@ -0,0 +1,203 @@
+package durableobject
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"syscall/js"
+	"time"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// SQLConnector returns a driver.Connector of the SQLite database of the Durable Object.
+// The database can be opened by sql.OpenDB.
+//   - The Durable Object class must use the SQLite storage backend (new_sqlite_classes in wrangler.toml).
+//   - Connections share the same database without isolation. Use OpenSQL, or call SetMaxOpenConns(1) on the *sql.DB,
+//     so that statements outside of a transaction don't fail with ErrTransactionOnOtherConn.
+//   - https://developers.cloudflare.com/durable-objects/api/sql-storage/
+func (s *Storage) SQLConnector() driver.Connector {
+	return &sqlConnector{storageObj: s.instance}
+}
+
+// OpenSQL opens the SQLite database of the Durable Object with SQLConnector.
+// The returned *sql.DB uses only one connection, so statements wait for the active transaction to end.
+func (s *Storage) OpenSQL() *sql.DB {
+	db := sql.OpenDB(s.SQLConnector())
+	db.SetMaxOpenConns(1)
+	return db
+}
+
+type sqlConnector struct {
+	storageObj js.Value
+	// txMu guards tx. Only one transaction can be active at a time since the database has no isolation between connections.
+	// For the same reason, statements on the other connections are rejected while tx is active.
+	txMu sync.Mutex
+	tx   *sqlTx
+}
+
+var _ driver.Connector = (*sqlConnector)(nil)
+
+func (c *sqlConnector) Connect(context.Context) (driver.Conn, error) {
+	return &sqlConn{
+		connector: c,
+		sqlObj:    c.storageObj.Get("sql"),
+	}, nil
+}
+
+func (c *sqlConnector) Driver() driver.Driver {
+	return sqlDriver{}
+}
+
+type sqlDriver struct{}
+
+func (sqlDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("durableobject: use sql.OpenDB with Storage.SQLConnector")
+}
+
+type sqlConn struct {
+	connector *sqlConnector
+	sqlObj    js.Value
+}
+
+var (
+	_ driver.Conn               = (*sqlConn)(nil)
+	_ driver.ConnBeginTx        = (*sqlConn)(nil)
+	_ driver.ConnPrepareContext = (*sqlConn)(nil)
+)
+
+func (c *sqlConn) Prepare(query string) (driver.Stmt, error) {
+	return &sqlStmt{conn: c, query: query}, nil
+}
+
+func (c *sqlConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.Prepare(query)
+}
+
+func (c *sqlConn) Close() error {
+	// do nothing
+	return nil
+}
+
+func (c *sqlConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("durableobject: Begin is deprecated and not implemented")
+}
+
+// exec executes query with args, and returns the cursor of the result.
+//   - If a transaction is active on another connection, ErrTransactionOnOtherConn is returned.
+func (c *sqlConn) exec(query string, args []driver.NamedValue) (js.Value, error) {
+	c.connector.txMu.Lock()
+	tx := c.connector.tx
+	c.connector.txMu.Unlock()
+	if tx != nil && tx.conn != c {
+		return js.Value{}, ErrTransactionOnOtherConn
+	}
+	execArgs := make([]any, 0, len(args)+1)
+	execArgs = append(execArgs, query)
+	for _, arg := range args {
+		v, err := toSQLValue(arg.Value)
+		if err != nil {
+			return js.Value{}, err
+		}
+		execArgs = append(execArgs, v)
+	}
+	return jsutil.TryCatchCall(c.sqlObj, "exec", execArgs...)
+}
+
+// toSQLValue converts driver.Value into a value which can be bound to a SQL statement.
+// Given []driver.NamedValue's `Name` field is ignored because SQL storage only supports positional parameters.
+func toSQLValue(v driver.Value) (any, error) {
+	switch v := v.(type) {
+	case []byte:
+		dst := jsutil.NewUint8Array(len(v))
+		if n := js.CopyBytesToJS(dst, v); n != len(v) {
+			return nil, errors.New("incomplete copy into Uint8Array")
+		}
+		return dst, nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	}
+	return v, nil
+}
+
+type sqlStmt struct {
+	conn  *sqlConn
+	query string
+}
+
+var (
+	_ driver.Stmt             = (*sqlStmt)(nil)
+	_ driver.StmtExecContext  = (*sqlStmt)(nil)
+	_ driver.StmtQueryContext = (*sqlStmt)(nil)
+)
+
+func (s *sqlStmt) Close() error {
+	// do nothing
+	return nil
+}
+
+// NumInput is not supported and always returns -1.
+func (s *sqlStmt) NumInput() int {
+	return -1
+}
+
+func (s *sqlStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("durableobject: Exec is deprecated and not implemented")
+}
+
+// ExecContext executes the statement and consumes all of its rows.
+func (s *sqlStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	cursor, err := s.conn.exec(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	// rowsRead and rowsWritten are fixed after all rows are consumed.
+	if _, err := jsutil.TryCatchCall(cursor, "toArray"); err != nil {
+		return nil, err
+	}
+	// changes() and last_insert_rowid() must be read before another statement runs.
+	metaCursor, err := s.conn.exec("SELECT changes() AS changes, last_insert_rowid() AS last_insert_rowid", nil)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := jsutil.TryCatchCall(metaCursor, "one")
+	if err != nil {
+		return nil, err
+	}
+	return &SQLResult{
+		rowsAffected: int64(meta.Get("changes").Int()),
+		lastInsertID: int64(meta.Get("last_insert_rowid").Int()),
+		rowsRead:     int64(cursor.Get("rowsRead").Int()),
+		rowsWritten:  int64(cursor.Get("rowsWritten").Int()),
+	}, nil
+}
+
+func (s *sqlStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("durableobject: Query is deprecated and not implemented")
+}
+
+// QueryContext executes the statement and returns its rows.
+// The rows are read from the cursor lazily.
+func (s *sqlStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	cursor, err := s.conn.exec(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return newSQLRows(cursor), nil
+}
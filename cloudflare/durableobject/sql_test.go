@@ -0,0 +1,286 @@
+package durableobject
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"syscall/js"
+	"testing"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// newFakeSQLStorage returns a DurableObjectStorage whose sql.exec returns the rows given by the test,
+// and records executed queries into log.
+// last_insert_rowid() returns the number of the statements executed before.
+var newFakeSQLStorage = js.Global().Get("Function").New("log", "results", `
+	const cursor = ({ columnNames = [], rows = [], rowsRead = 0, rowsWritten = 0 }) => ({
+		columnNames,
+		rowsRead,
+		rowsWritten,
+		raw() {
+			return rows[Symbol.iterator]();
+		},
+		toArray() {
+			return rows.map((row) => Object.fromEntries(columnNames.map((name, i) => [name, row[i]])));
+		},
+		one() {
+			return this.toArray()[0];
+		},
+	});
+	let lastInsertRowid = 0;
+	return {
+		sql: {
+			exec(query, ...args) {
+				if (query.startsWith("SELECT changes()")) {
+					return cursor({ columnNames: ["changes", "last_insert_rowid"], rows: [[1, lastInsertRowid]] });
+				}
+				log.push([query, ...args].join(" "));
+				lastInsertRowid++;
+				if (query === "INVALID") {
+					throw new Error("syntax error");
+				}
+				if (query === "CONSTRAINT") {
+					return {
+						toArray() {
+							throw new Error("UNIQUE constraint failed");
+						},
+					};
+				}
+				return cursor(results[query] || {});
+			},
+		},
+		transactionSync(fn) {
+			log.push("BEGIN");
+			try {
+				const result = fn();
+				log.push("COMMIT");
+				return result;
+			} catch (e) {
+				log.push("ROLLBACK");
+				throw e;
+			}
+		},
+	};
+`)
+
+func newTestDB(t *testing.T, results map[string]any) (*sql.DB, js.Value) {
+	t.Helper()
+	log := js.Global().Get("Array").New()
+	s := &Storage{instance: newFakeSQLStorage.Invoke(log, js.ValueOf(results))}
+	db := sql.OpenDB(s.SQLConnector())
+	t.Cleanup(func() { db.Close() })
+	return db, log
+}
+
+func logString(log js.Value) string {
+	return log.Call("join", ";").String()
+}
+
+func TestSQL_Query(t *testing.T) {
+	db, log := newTestDB(t, map[string]any{
+		"SELECT id, name, score FROM users WHERE id > ?": map[string]any{
+			"columnNames": []any{"id", "name", "score"},
+			"rows": []any{
+				[]any{1, "alice", 1.5},
+				[]any{2, "bob", nil},
+			},
+		},
+	})
+	rows, err := db.Query("SELECT id, name, score FROM users WHERE id > ?", 0)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var (
+			id    int64
+			name  string
+			score sql.NullFloat64
+		)
+		if err := rows.Scan(&id, &name, &score); err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		got = append(got, name)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("rows.Err() = %v", err)
+	}
+	if strings.Join(got, ",") != "alice,bob" {
+		t.Errorf("names = %v, want [alice bob]", got)
+	}
+	if want := "SELECT id, name, score FROM users WHERE id > ? 0"; logString(log) != want {
+		t.Errorf("log = %q, want %q", logString(log), want)
+	}
+}
+
+func TestSQL_Exec(t *testing.T) {
+	db, _ := newTestDB(t, map[string]any{
+		"INSERT INTO users (name) VALUES (?)": map[string]any{
+			"rowsRead":    1,
+			"rowsWritten": 2,
+		},
+	})
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("Conn() error = %v", err)
+	}
+	defer conn.Close()
+	err = conn.Raw(func(driverConn any) error {
+		stmt, err := driverConn.(*sqlConn).Prepare("INSERT INTO users (name) VALUES (?)")
+		if err != nil {
+			return err
+		}
+		res, err := stmt.(*sqlStmt).ExecContext(context.Background(), nil)
+		if err != nil {
+			return err
+		}
+		r := res.(*SQLResult)
+		if id, _ := r.LastInsertId(); id != 1 {
+			t.Errorf("LastInsertId() = %d, want 1", id)
+		}
+		if n, _ := r.RowsAffected(); n != 1 {
+			t.Errorf("RowsAffected() = %d, want 1", n)
+		}
+		if r.RowsRead() != 1 || r.RowsWritten() != 2 {
+			t.Errorf("RowsRead(), RowsWritten() = %d, %d, want 1, 2", r.RowsRead(), r.RowsWritten())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Raw() error = %v", err)
+	}
+
+	if _, err := db.Exec("INVALID"); err == nil || !strings.Contains(err.Error(), "syntax error") {
+		t.Errorf("Exec() error = %v, want syntax error", err)
+	}
+	if _, err := db.Exec("CONSTRAINT"); err == nil || !strings.Contains(err.Error(), "UNIQUE constraint failed") {
+		t.Errorf("Exec() error = %v, want UNIQUE constraint failed", err)
+	}
+}
+
+func TestSQL_Exec_ResultAfterOtherStatement(t *testing.T) {
+	db, _ := newTestDB(t, nil)
+	res, err := db.Exec("INSERT INTO users (name) VALUES ('alice')")
+	if err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO users (name) VALUES ('bob')"); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if id, err := res.LastInsertId(); err != nil || id != 1 {
+		t.Errorf("LastInsertId() = %d, %v, want 1", id, err)
+	}
+}
+
+func TestSQL_Transaction(t *testing.T) {
+	db, log := newTestDB(t, nil)
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	if _, err := tx.Exec("UPDATE a"); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	tx, err = db.Begin()
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	if _, err := tx.Exec("UPDATE b"); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+
+	if want := "BEGIN;UPDATE a;COMMIT;BEGIN;UPDATE b;ROLLBACK"; logString(log) != want {
+		t.Errorf("log = %q, want %q", logString(log), want)
+	}
+}
+
+func TestSQL_NestedTransaction(t *testing.T) {
+	db, _ := newTestDB(t, nil)
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	defer tx.Rollback()
+	if _, err := db.Begin(); !errors.Is(err, ErrNestedTransaction) {
+		t.Errorf("Begin() error = %v, want %v", err, ErrNestedTransaction)
+	}
+}
+
+func TestSQL_TransactionOnOtherConn(t *testing.T) {
+	db, log := newTestDB(t, nil)
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	// db.Exec uses another connection since tx holds one.
+	if _, err := db.Exec("UPDATE other"); !errors.Is(err, ErrTransactionOnOtherConn) {
+		t.Errorf("Exec() error = %v, want %v", err, ErrTransactionOnOtherConn)
+	}
+	if _, err := tx.Exec("UPDATE a"); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if _, err := db.Exec("UPDATE other"); err != nil {
+		t.Fatalf("Exec() after Commit error = %v", err)
+	}
+	if want := "BEGIN;UPDATE a;COMMIT;UPDATE other"; logString(log) != want {
+		t.Errorf("log = %q, want %q", logString(log), want)
+	}
+}
+
+func TestSQL_AwaitInTransaction(t *testing.T) {
+	db, _ := newTestDB(t, nil)
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	promise := jsutil.PromiseClass.Call("resolve", 1)
+	if _, err := jsutil.AwaitPromise(promise); !errors.Is(err, ErrAwaitInTransaction) {
+		t.Errorf("AwaitPromise() error = %v, want %v", err, ErrAwaitInTransaction)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if _, err := jsutil.AwaitPromise(promise); err != nil {
+		t.Errorf("AwaitPromise() after Rollback error = %v", err)
+	}
+}
+
+func TestStorage_OpenSQL(t *testing.T) {
+	log := js.Global().Get("Array").New()
+	s := &Storage{instance: newFakeSQLStorage.Invoke(log, js.ValueOf(map[string]any{}))}
+	db := s.OpenSQL()
+	defer db.Close()
+	if n := db.Stats().MaxOpenConnections; n != 1 {
+		t.Errorf("MaxOpenConnections = %d, want 1", n)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	if _, err := tx.Exec("UPDATE a"); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if _, err := db.Exec("UPDATE b"); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if want := "BEGIN;UPDATE a;COMMIT;UPDATE b"; logString(log) != want {
+		t.Errorf("log = %q, want %q", logString(log), want)
+	}
+}
@@ -0,0 +1,36 @@
+package durableobject
+
+import (
+	"database/sql/driver"
+)
+
+// SQLResult is the driver.Result of a statement executed on the SQLite database of a Durable Object.
+//   - database/sql wraps driver.Result, so use (*sql.Conn).Raw to access RowsRead and RowsWritten.
+type SQLResult struct {
+	rowsAffected int64
+	lastInsertID int64
+	rowsRead     int64
+	rowsWritten  int64
+}
+
+var _ driver.Result = (*SQLResult)(nil)
+
+// LastInsertId returns the rowid of the last inserted row.
+func (r *SQLResult) LastInsertId() (int64, error) {
+	return r.lastInsertID, nil
+}
+
+// RowsAffected returns the number of rows changed by an update, insert, or delete.
+func (r *SQLResult) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+// RowsRead returns the number of rows read by the statement. This is used for billing.
+func (r *SQLResult) RowsRead() int64 {
+	return r.rowsRead
+}
+
+// RowsWritten returns the number of rows written by the statement including indexes. This is used for billing.
+func (r *SQLResult) RowsWritten() int64 {
+	return r.rowsWritten
+}
@@ -0,0 +1,86 @@
+package durableobject
+
+import (
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math"
+	"syscall/js"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// sqlRows iterates the rows of a SQL storage cursor.
+type sqlRows struct {
+	cursor   js.Value
+	iterator js.Value
+	columns  []string
+}
+
+var _ driver.Rows = (*sqlRows)(nil)
+
+func newSQLRows(cursor js.Value) *sqlRows {
+	colsArray := cursor.Get("columnNames")
+	cols := make([]string, colsArray.Length())
+	for i := range cols {
+		cols[i] = colsArray.Index(i).String()
+	}
+	return &sqlRows{
+		cursor:   cursor,
+		iterator: cursor.Call("raw"),
+		columns:  cols,
+	}
+}
+
+// Columns returns column names of the cursor.
+func (r *sqlRows) Columns() []string {
+	return r.columns
+}
+
+func (r *sqlRows) Close() error {
+	// do nothing
+	return nil
+}
+
+func (r *sqlRows) Next(dest []driver.Value) error {
+	next := r.iterator.Call("next")
+	if next.Get("done").Bool() {
+		return io.EOF
+	}
+	rowArray := next.Get("value")
+	for i := 0; i < rowArray.Length(); i++ {
+		v, err := toDriverValue(rowArray.Index(i))
+		if err != nil {
+			return err
+		}
+		dest[i] = v
+	}
+	return nil
+}
+
+// toDriverValue converts a column value of SQL storage into driver.Value.
+// The column value is `null | Number | String | ArrayBuffer`.
+func toDriverValue(v js.Value) (driver.Value, error) {
+	switch v.Type() {
+	case js.TypeNull:
+		return nil, nil
+	case js.TypeNumber:
+		fv := v.Float()
+		// if the value can be treated as integral value, return as int64.
+		if !math.IsNaN(fv) && !math.IsInf(fv, 0) && fv == math.Trunc(fv) {
+			return int64(fv), nil
+		}
+		return fv, nil
+	case js.TypeString:
+		return v.String(), nil
+	case js.TypeObject:
+		// handle BLOB type (ArrayBuffer).
+		src := jsutil.Uint8ArrayClass.New(v)
+		dst := make([]byte, src.Length())
+		if n := js.CopyBytesToGo(dst, src); n != len(dst) {
+			return nil, errors.New("incomplete copy from Uint8Array")
+		}
+		return dst, nil
+	}
+	return nil, errors.New("durableobject: unexpected column value type")
+}
@@ -0,0 +1,125 @@
+package durableobject
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"syscall/js"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+var (
+	// ErrNestedTransaction is returned when a transaction is started while another one is active.
+	ErrNestedTransaction = errors.New("durableobject: nested transactions are not supported")
+	// ErrTransactionOnOtherConn is returned when a statement is executed on a connection
+	// while a transaction is active on another connection.
+	ErrTransactionOnOtherConn = errors.New("durableobject: a transaction is active on another connection")
+	// ErrAwaitInTransaction is returned when a Promise (e.g. fetch, KV or Storage.Get) is awaited while a transaction is active.
+	ErrAwaitInTransaction = jsutil.ErrEventLoopBlocked
+	// errRollback is thrown into transactionSync to roll back the transaction.
+	errRollback = errors.New("durableobject: transaction rolled back")
+)
+
+// sqlTx is a transaction run by storage.transactionSync.
+//
+// transactionSync calls its callback synchronously, so the callback blocks until the transaction ends,
+// while statements are executed by the goroutine which began the transaction.
+// The JS event loop is blocked during the transaction, so awaiting any Promise
+// (e.g. fetch, KV or Storage.Get) before Commit or Rollback returns ErrAwaitInTransaction.
+type sqlTx struct {
+	connector *sqlConnector
+	// conn is the connection which began the transaction. Statements on other connections are rejected.
+	conn     *sqlConn
+	endCh    chan bool
+	resultCh chan error
+}
+
+var _ driver.Tx = (*sqlTx)(nil)
+
+// BeginTx begins a transaction by storage.transactionSync.
+//   - Isolation levels and read-only transactions are not supported.
+//   - Only one transaction can be active at a time. If another one is active, ErrNestedTransaction is returned.
+//   - While the transaction is active, statements on other connections return ErrTransactionOnOtherConn,
+//     and awaiting a Promise returns ErrAwaitInTransaction.
+func (c *sqlConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if opts.Isolation != driver.IsolationLevel(0) || opts.ReadOnly {
+		return nil, errors.New("durableobject: transaction options are not supported")
+	}
+	connector := c.connector
+	connector.txMu.Lock()
+	defer connector.txMu.Unlock()
+	if connector.tx != nil {
+		return nil, ErrNestedTransaction
+	}
+	tx := &sqlTx{
+		connector: connector,
+		conn:      c,
+		endCh:     make(chan bool),
+		resultCh:  make(chan error, 1),
+	}
+	startedCh := make(chan struct{})
+	go func() {
+		var started bool
+		var callback js.Func
+		callback = js.FuncOf(func(js.Value, []js.Value) any {
+			unblock := jsutil.BlockEventLoop()
+			defer unblock()
+			started = true
+			close(startedCh)
+			result := jsutil.NewObject()
+			if commit := <-tx.endCh; !commit {
+				result.Set("error", jsutil.Error(errRollback.Error()))
+			}
+			return result
+		})
+		defer callback.Release()
+		body := js.Global().Get("callOrThrow").Call("bind", js.Null(), callback)
+		_, err := jsutil.TryCatchCall(connector.storageObj, "transactionSync", body)
+		tx.resultCh <- err
+		if !started {
+			// transactionSync failed before calling the callback.
+			close(startedCh)
+		}
+	}()
+	<-startedCh
+	select {
+	case err := <-tx.resultCh:
+		if err == nil {
+			err = errors.New("durableobject: transaction ended unexpectedly")
+		}
+		return nil, err
+	default:
+	}
+	connector.tx = tx
+	return tx, nil
+}
+
+func (tx *sqlTx) end(commit bool) error {
+	tx.connector.txMu.Lock()
+	defer tx.connector.txMu.Unlock()
+	if tx.connector.tx != tx {
+		return errors.New("durableobject: transaction has already been committed or rolled back")
+	}
+	tx.connector.tx = nil
+	tx.endCh <- commit
+	err := <-tx.resultCh
+	if !commit && err != nil {
+		// The error thrown to roll back the transaction is expected.
+		return nil
+	}
+	return err
+}
+
+// Commit commits the transaction.
+func (tx *sqlTx) Commit() error {
+	return tx.end(true)
+}
+
+// Rollback rolls back the transaction.
+func (tx *sqlTx) Rollback() error {
+	return tx.end(false)
+}
@@ -0,0 +1,46 @@
+package durableobject
+
+import (
+	"syscall/js"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// State represents the state of a Durable Object instance.
+//   - https://developers.cloudflare.com/durable-objects/api/state/
+type State struct {
+	instance js.Value
+}
+
+// ID returns the ID of the Durable Object instance as a hex string.
+func (s *State) ID() string {
+	return s.instance.Get("id").Call("toString").String()
+}
+
+// Name returns the name of the Durable Object instance.
+// If the instance was not created by a name, an empty string is returned.
+func (s *State) Name() string {
+	name := s.instance.Get("id").Get("name")
+	if name.IsUndefined() || name.IsNull() {
+		return ""
+	}
+	return name.String()
+}
+
+// BlockConcurrencyWhile runs fn while blocking the delivery of other events to the Durable Object instance.
+// This is typically used in the Constructor to load the initial state from storage.
+//   - https://developers.cloudflare.com/durable-objects/api/state/#blockconcurrencywhile
+func (s *State) BlockConcurrencyWhile(fn func() error) error {
+	var callback js.Func
+	callback = js.FuncOf(func(js.Value, []js.Value) any {
+		defer callback.Release()
+		return newPromise(func() (js.Value, error) {
+			if err := fn(); err != nil {
+				return js.Value{}, err
+			}
+			return js.Undefined(), nil
+		})
+	})
+	_, err := jsutil.AwaitPromise(s.instance.Call("blockConcurrencyWhile", callback))
+	return err
+}
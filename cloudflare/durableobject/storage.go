@@ -0,0 +1,249 @@
+package durableobject
+
+import (
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare/codec"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// Storage represents the transactional storage of a Durable Object.
+//   - Values are encoded into JS values by the Codec of the Storage. codec.JSON is used by default.
+//   - https://developers.cloudflare.com/durable-objects/api/storage-api/
+type Storage struct {
+	instance js.Value
+	codec    codec.Codec
+}
+
+// Storage returns the Storage of the Durable Object instance.
+func (s *State) Storage() *Storage {
+	return &Storage{
+		instance: s.instance.Get("storage"),
+		codec:    codec.JSON,
+	}
+}
+
+// WithCodec returns a copy of the Storage which encodes values by c.
+func (s *Storage) WithCodec(c codec.Codec) *Storage {
+	return &Storage{
+		instance: s.instance,
+		codec:    c,
+	}
+}
+
+// Value represents a value read from Storage.
+type Value = codec.Value
+
+// Entry represents a key-value pair read from Storage.
+type Entry struct {
+	Key   string
+	Value *Value
+}
+
+// GetOptions represents Durable Object storage get options.
+//   - https://developers.cloudflare.com/durable-objects/api/storage-api/#get
+type GetOptions struct {
+	AllowConcurrency bool
+	NoCache          bool
+}
+
+func (opts *GetOptions) toJS() js.Value {
+	if opts == nil {
+		return js.Undefined()
+	}
+	obj := jsutil.NewObject()
+	if opts.AllowConcurrency {
+		obj.Set("allowConcurrency", true)
+	}
+	if opts.NoCache {
+		obj.Set("noCache", true)
+	}
+	return obj
+}
+
+// PutOptions represents Durable Object storage put options.
+// These options are also used by the delete methods.
+//   - https://developers.cloudflare.com/durable-objects/api/storage-api/#put
+type PutOptions struct {
+	AllowUnconfirmed bool
+	NoCache          bool
+}
+
+func (opts *PutOptions) toJS() js.Value {
+	if opts == nil {
+		return js.Undefined()
+	}
+	obj := jsutil.NewObject()
+	if opts.AllowUnconfirmed {
+		obj.Set("allowUnconfirmed", true)
+	}
+	if opts.NoCache {
+		obj.Set("noCache", true)
+	}
+	return obj
+}
+
+// ListOptions represents Durable Object storage list options.
+//   - https://developers.cloudflare.com/durable-objects/api/storage-api/#list
+type ListOptions struct {
+	// Start is the key to start the list at (inclusive).
+	Start string
+	// StartAfter is the key to start the list after (exclusive). This can't be used with Start.
+	StartAfter string
+	// End is the key to stop the list at (exclusive).
+	End     string
+	Prefix  string
+	Reverse bool
+	// Limit is the maximum number of entries. The value `0` means no limit.
+	Limit            int
+	AllowConcurrency bool
+	NoCache          bool
+}
+
+func (opts *ListOptions) toJS() js.Value {
+	if opts == nil {
+		return js.Undefined()
+	}
+	obj := jsutil.NewObject()
+	if opts.Start != "" {
+		obj.Set("start", opts.Start)
+	}
+	if opts.StartAfter != "" {
+		obj.Set("startAfter", opts.StartAfter)
+	}
+	if opts.End != "" {
+		obj.Set("end", opts.End)
+	}
+	if opts.Prefix != "" {
+		obj.Set("prefix", opts.Prefix)
+	}
+	if opts.Reverse {
+		obj.Set("reverse", true)
+	}
+	if opts.Limit != 0 {
+		obj.Set("limit", opts.Limit)
+	}
+	if opts.AllowConcurrency {
+		obj.Set("allowConcurrency", true)
+	}
+	if opts.NoCache {
+		obj.Set("noCache", true)
+	}
+	return obj
+}
+
+// Get gets the value stored with key, and decodes it into the value pointed to by ptr.
+//   - if the key doesn't exist, returns false.
+func (s *Storage) Get(key string, ptr any, opts *GetOptions) (bool, error) {
+	v, err := jsutil.AwaitPromise(s.instance.Call("get", key, opts.toJS()))
+	if err != nil {
+		return false, err
+	}
+	if v.IsUndefined() {
+		return false, nil
+	}
+	if err := s.codec.Decode(v, ptr); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetMultiple gets the values stored with keys.
+//   - keys which don't exist are not included in the result.
+//   - up to 128 keys can be given at once.
+func (s *Storage) GetMultiple(keys []string, opts *GetOptions) (map[string]*Value, error) {
+	v, err := jsutil.AwaitPromise(s.instance.Call("get", toJSKeys(keys), opts.toJS()))
+	if err != nil {
+		return nil, err
+	}
+	entries := s.toEntries(v)
+	values := make(map[string]*Value, len(entries))
+	for _, e := range entries {
+		values[e.Key] = e.Value
+	}
+	return values, nil
+}
+
+// Put encodes value and stores it with key.
+func (s *Storage) Put(key string, value any, opts *PutOptions) error {
+	v, err := s.codec.Encode(value)
+	if err != nil {
+		return err
+	}
+	_, err = jsutil.AwaitPromise(s.instance.Call("put", key, v, opts.toJS()))
+	return err
+}
+
+// PutMultiple encodes values and stores them with their keys.
+//   - up to 128 entries can be given at once.
+func (s *Storage) PutMultiple(entries map[string]any, opts *PutOptions) error {
+	obj := jsutil.NewObject()
+	for key, value := range entries {
+		v, err := s.codec.Encode(value)
+		if err != nil {
+			return err
+		}
+		obj.Set(key, v)
+	}
+	_, err := jsutil.AwaitPromise(s.instance.Call("put", obj, opts.toJS()))
+	return err
+}
+
+// Delete deletes the value stored with key.
+//   - returns true if the key existed.
+func (s *Storage) Delete(key string, opts *PutOptions) (bool, error) {
+	v, err := jsutil.AwaitPromise(s.instance.Call("delete", key, opts.toJS()))
+	if err != nil {
+		return false, err
+	}
+	return v.Bool(), nil
+}
+
+// DeleteMultiple deletes the values stored with keys.
+//   - returns the number of keys which existed.
+//   - up to 128 keys can be given at once.
+func (s *Storage) DeleteMultiple(keys []string, opts *PutOptions) (int, error) {
+	v, err := jsutil.AwaitPromise(s.instance.Call("delete", toJSKeys(keys), opts.toJS()))
+	if err != nil {
+		return 0, err
+	}
+	return v.Int(), nil
+}
+
+// DeleteAll deletes all values stored in the Durable Object.
+func (s *Storage) DeleteAll(opts *PutOptions) error {
+	_, err := jsutil.AwaitPromise(s.instance.Call("deleteAll", opts.toJS()))
+	return err
+}
+
+// List lists the entries stored in the Durable Object in the order of their keys.
+func (s *Storage) List(opts *ListOptions) ([]*Entry, error) {
+	v, err := jsutil.AwaitPromise(s.instance.Call("list", opts.toJS()))
+	if err != nil {
+		return nil, err
+	}
+	return s.toEntries(v), nil
+}
+
+// toEntries converts a JS Map into entries in its iteration order.
+func (s *Storage) toEntries(m js.Value) []*Entry {
+	var entries []*Entry
+	cb := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		entries = append(entries, &Entry{
+			Key:   args[1].String(),
+			Value: codec.NewValue(args[0], s.codec),
+		})
+		return js.Undefined()
+	})
+	defer cb.Release()
+	m.Call("forEach", cb)
+	return entries
+}
+
+func toJSKeys(keys []string) js.Value {
+	arr := jsutil.NewArray(len(keys))
+	for i, key := range keys {
+		arr.SetIndex(i, key)
+	}
+	return arr
+}
@@ -0,0 +1,244 @@
+package durableobject
+
+import (
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/codec"
+)
+
+// newFakeStorage returns a minimal in-memory implementation of DurableObjectStorage.
+var newFakeStorage = js.Global().Get("Function").New(`
+	const data = new Map();
+	let alarm = null;
+	const sorted = () => new Map([...data.entries()].sort(([a], [b]) => (a < b ? -1 : a > b ? 1 : 0)));
+	const storage = {
+		async get(key) {
+			if (Array.isArray(key)) {
+				return new Map(key.filter((k) => data.has(k)).map((k) => [k, data.get(k)]));
+			}
+			return data.get(key);
+		},
+		async put(key, value) {
+			if (typeof key === "object") {
+				for (const [k, v] of Object.entries(key)) {
+					data.set(k, v);
+				}
+				return;
+			}
+			data.set(key, value);
+		},
+		async delete(key) {
+			if (Array.isArray(key)) {
+				return key.filter((k) => data.delete(k)).length;
+			}
+			return data.delete(key);
+		},
+		async deleteAll() {
+			data.clear();
+		},
+		async list(opts = {}) {
+			let entries = [...sorted().entries()];
+			if (opts.prefix !== undefined) {
+				entries = entries.filter(([k]) => k.startsWith(opts.prefix));
+			}
+			if (opts.start !== undefined) {
+				entries = entries.filter(([k]) => k >= opts.start);
+			}
+			if (opts.end !== undefined) {
+				entries = entries.filter(([k]) => k < opts.end);
+			}
+			if (opts.reverse) {
+				entries.reverse();
+			}
+			if (opts.limit !== undefined) {
+				entries = entries.slice(0, opts.limit);
+			}
+			return new Map(entries);
+		},
+		async getAlarm() {
+			return alarm;
+		},
+		async setAlarm(t) {
+			alarm = t.getTime();
+		},
+		async deleteAlarm() {
+			alarm = null;
+		},
+		async transaction(fn) {
+			const snapshot = new Map(data);
+			let rolledBack = false;
+			const txn = { ...storage, rollback() { rolledBack = true; } };
+			try {
+				const result = await fn(txn);
+				if (rolledBack) {
+					data.clear();
+					snapshot.forEach((v, k) => data.set(k, v));
+				}
+				return result;
+			} catch (e) {
+				data.clear();
+				snapshot.forEach((v, k) => data.set(k, v));
+				throw e;
+			}
+		},
+	};
+	return storage;
+`)
+
+func newTestStorage() *Storage {
+	return &Storage{instance: newFakeStorage.Invoke(), codec: codec.JSON}
+}
+
+type counter struct {
+	Value int `json:"value"`
+}
+
+func TestStorage_GetPut(t *testing.T) {
+	s := newTestStorage()
+	var c counter
+	found, err := s.Get("counter", &c, nil)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if found {
+		t.Fatal("Get() found a missing key")
+	}
+
+	if err := s.Put("counter", counter{Value: 3}, nil); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	found, err = s.Get("counter", &c, nil)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !found || c.Value != 3 {
+		t.Errorf("Get() = %v, %+v, want true, {Value:3}", found, c)
+	}
+
+	deleted, err := s.Delete("counter", nil)
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if !deleted {
+		t.Error("Delete() = false, want true")
+	}
+}
+
+func TestStorage_Multiple(t *testing.T) {
+	s := newTestStorage()
+	if err := s.PutMultiple(map[string]any{"a": 1, "b": 2, "c": 3}, nil); err != nil {
+		t.Fatalf("PutMultiple() error = %v", err)
+	}
+	values, err := s.GetMultiple([]string{"a", "c", "z"}, nil)
+	if err != nil {
+		t.Fatalf("GetMultiple() error = %v", err)
+	}
+	if len(values) != 2 {
+		t.Fatalf("len(GetMultiple()) = %d, want 2", len(values))
+	}
+	var c int
+	if err := values["c"].Decode(&c); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if c != 3 {
+		t.Errorf("c = %d, want 3", c)
+	}
+
+	n, err := s.DeleteMultiple([]string{"a", "z"}, nil)
+	if err != nil {
+		t.Fatalf("DeleteMultiple() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("DeleteMultiple() = %d, want 1", n)
+	}
+	if err := s.DeleteAll(nil); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	entries, err := s.List(nil)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("len(List()) = %d, want 0", len(entries))
+	}
+}
+
+func TestStorage_List(t *testing.T) {
+	s := newTestStorage()
+	if err := s.PutMultiple(map[string]any{"user:1": 1, "user:2": 2, "user:3": 3, "room:1": 4}, nil); err != nil {
+		t.Fatalf("PutMultiple() error = %v", err)
+	}
+	entries, err := s.List(&ListOptions{Prefix: "user:", Reverse: true, Limit: 2})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	var keys []string
+	for _, e := range entries {
+		keys = append(keys, e.Key)
+	}
+	if len(keys) != 2 || keys[0] != "user:3" || keys[1] != "user:2" {
+		t.Errorf("keys = %v, want [user:3 user:2]", keys)
+	}
+}
+
+func TestStorage_Transaction(t *testing.T) {
+	s := newTestStorage()
+	if err := s.Put("balance", 10, nil); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	err := s.Transaction(func(txn *Transaction) error {
+		var balance int
+		if _, err := txn.Get("balance", &balance, nil); err != nil {
+			return err
+		}
+		return txn.Put("balance", balance+5, nil)
+	})
+	if err != nil {
+		t.Fatalf("Transaction() error = %v", err)
+	}
+
+	err = s.Transaction(func(txn *Transaction) error {
+		if err := txn.Put("balance", 0, nil); err != nil {
+			return err
+		}
+		panic("insufficient balance")
+	})
+	if err == nil {
+		t.Fatal("Transaction() must return an error when fn panics")
+	}
+
+	var balance int
+	if _, err := s.Get("balance", &balance, nil); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if balance != 15 {
+		t.Errorf("balance = %d, want 15", balance)
+	}
+}
+
+func TestStorage_Alarm(t *testing.T) {
+	s := newTestStorage()
+	if _, ok, err := s.GetAlarm(nil); err != nil || ok {
+		t.Fatalf("GetAlarm() = _, %v, %v, want false, nil", ok, err)
+	}
+	want := time.UnixMilli(1700000000000)
+	if err := s.SetAlarm(want, nil); err != nil {
+		t.Fatalf("SetAlarm() error = %v", err)
+	}
+	got, ok, err := s.GetAlarm(nil)
+	if err != nil || !ok {
+		t.Fatalf("GetAlarm() = _, %v, %v, want true, nil", ok, err)
+	}
+	if !got.Equal(want) {
+		t.Errorf("GetAlarm() = %v, want %v", got, want)
+	}
+	if err := s.DeleteAlarm(nil); err != nil {
+		t.Fatalf("DeleteAlarm() error = %v", err)
+	}
+	if _, ok, _ := s.GetAlarm(nil); ok {
+		t.Error("GetAlarm() returned an alarm after DeleteAlarm")
+	}
+}
@@ -0,0 +1,55 @@
+package durableobject
+
+import (
+	"runtime/debug"
+	"syscall/js"
+
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/recovery"
+)
+
+// Transaction represents a transaction of Storage.
+// All methods of Storage are available on Transaction.
+type Transaction struct {
+	*Storage
+}
+
+// Rollback rolls back the transaction.
+// The changes made in the transaction are discarded even if the transaction function returns nil.
+func (txn *Transaction) Rollback() {
+	txn.instance.Call("rollback")
+}
+
+// Transaction runs fn in a transaction.
+//   - If fn returns an error or panics, the transaction is rolled back and the error is returned.
+//   - Storage must not be used directly in fn. Use txn instead.
+//   - https://developers.cloudflare.com/durable-objects/api/storage-api/#transaction
+func (s *Storage) Transaction(fn func(txn *Transaction) error) error {
+	var fnErr error
+	var callback js.Func
+	callback = js.FuncOf(func(_ js.Value, args []js.Value) any {
+		defer callback.Release()
+		txn := &Transaction{
+			Storage: &Storage{instance: args[0], codec: s.codec},
+		}
+		return newPromise(func() (_ js.Value, err error) {
+			defer func() {
+				if v := recover(); v != nil {
+					recovery.Report(nil, v, debug.Stack())
+					err = recovery.Error(v)
+				}
+				// Returning an error to the runtime rolls back the transaction.
+				fnErr = err
+			}()
+			if err := fn(txn); err != nil {
+				return js.Value{}, err
+			}
+			return js.Undefined(), nil
+		})
+	})
+	_, err := jsutil.AwaitPromise(s.instance.Call("transaction", callback))
+	if fnErr != nil {
+		return fnErr
+	}
+	return err
+}
@@ -0,0 +1,230 @@
+package durableobject
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare/codec"
+	"github.com/syumai/workers/cloudflare/websocket"
+	"github.com/syumai/workers/internal/jshttp"
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/recovery"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+// WebSocket represents a hibernatable WebSocket accepted by State.AcceptWebSocket.
+// Messages sent to the WebSocket are delivered to the handlers of the Durable Object
+// (WebSocketMessageHandler, WebSocketCloseHandler and WebSocketErrorHandler) instead of the WebSocket itself.
+//   - https://developers.cloudflare.com/durable-objects/best-practices/websockets/#websocket-hibernation-api
+type WebSocket struct {
+	instance js.Value
+}
+
+// Send sends a message to the WebSocket.
+//   - messageType must be websocket.TextMessage or websocket.BinaryMessage.
+func (ws *WebSocket) Send(messageType websocket.MessageType, data []byte) error {
+	switch messageType {
+	case websocket.TextMessage:
+		_, err := jsutil.TryCatchCall(ws.instance, "send", string(data))
+		return err
+	case websocket.BinaryMessage:
+		ua := jsutil.NewUint8Array(len(data))
+		js.CopyBytesToJS(ua, data)
+		_, err := jsutil.TryCatchCall(ws.instance, "send", ua)
+		return err
+	}
+	return fmt.Errorf("durableobject: unsupported message type: %v", messageType)
+}
+
+// Close closes the WebSocket with the given close code and reason.
+func (ws *WebSocket) Close(code int, reason string) error {
+	_, err := jsutil.TryCatchCall(ws.instance, "close", code, reason)
+	return err
+}
+
+// SerializeAttachment encodes v by codec.JSON and attaches it to the WebSocket.
+// The attachment survives hibernation of the Durable Object. Its size is limited to 2,048 bytes.
+func (ws *WebSocket) SerializeAttachment(v any) error {
+	obj, err := codec.JSON.Encode(v)
+	if err != nil {
+		return err
+	}
+	_, err = jsutil.TryCatchCall(ws.instance, "serializeAttachment", obj)
+	return err
+}
+
+// DeserializeAttachment decodes the attachment of the WebSocket into the value pointed to by ptr.
+//   - if no attachment is set, returns false.
+func (ws *WebSocket) DeserializeAttachment(ptr any) (bool, error) {
+	v, err := jsutil.TryCatchCall(ws.instance, "deserializeAttachment")
+	if err != nil {
+		return false, err
+	}
+	if v.IsNull() || v.IsUndefined() {
+		return false, nil
+	}
+	if err := codec.JSON.Decode(v, ptr); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// AcceptWebSocket upgrades the HTTP request to a hibernatable WebSocket.
+//   - tags can be used to find the WebSocket by State.GetWebSockets.
+//   - If the request is not a WebSocket upgrade request, 426 Upgrade Required is responded and websocket.ErrNotWebSocketRequest is returned.
+//   - If the WebSocket can't be accepted, 500 Internal Server Error is responded and the error is returned.
+//   - https://developers.cloudflare.com/durable-objects/api/state/#acceptwebsocket
+func (s *State) AcceptWebSocket(w http.ResponseWriter, r *http.Request, tags ...string) (*WebSocket, error) {
+	if !websocket.IsWebSocketUpgrade(r) {
+		w.Header().Set("Upgrade", "websocket")
+		http.Error(w, http.StatusText(http.StatusUpgradeRequired), http.StatusUpgradeRequired)
+		return nil, websocket.ErrNotWebSocketRequest
+	}
+	var ws *WebSocket
+	ok, err := jshttp.UpgradeToWebSocket(w, func(server js.Value) error {
+		ws = &WebSocket{instance: server}
+		_, err := jsutil.TryCatchCall(s.instance, "acceptWebSocket", server, toJSStrings(tags))
+		return err
+	})
+	if !ok {
+		return nil, websocket.ErrUpgradeNotSupported
+	}
+	if err != nil {
+		// the WebSocket is not handed to the client, so the request fails.
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil, err
+	}
+	return ws, nil
+}
+
+// GetWebSockets returns the WebSockets accepted by AcceptWebSocket.
+//   - if tag is not empty, only the WebSockets with the tag are returned.
+func (s *State) GetWebSockets(tag string) []*WebSocket {
+	var arr js.Value
+	if tag == "" {
+		arr = s.instance.Call("getWebSockets")
+	} else {
+		arr = s.instance.Call("getWebSockets", tag)
+	}
+	sockets := make([]*WebSocket, arr.Length())
+	for i := range sockets {
+		sockets[i] = &WebSocket{instance: arr.Index(i)}
+	}
+	return sockets
+}
+
+// GetTags returns the tags of the WebSocket given to AcceptWebSocket.
+func (s *State) GetTags(ws *WebSocket) ([]string, error) {
+	arr, err := jsutil.TryCatchCall(s.instance, "getTags", ws.instance)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]string, arr.Length())
+	for i := range tags {
+		tags[i] = arr.Index(i).String()
+	}
+	return tags, nil
+}
+
+// SetWebSocketAutoResponse sets the response which the runtime sends without waking up the Durable Object
+// when a WebSocket receives the request message. This is typically used for ping/pong.
+//   - if request is empty, the auto response is removed.
+//   - https://developers.cloudflare.com/durable-objects/api/state/#setwebsocketautoresponse
+func (s *State) SetWebSocketAutoResponse(request, response string) error {
+	if request == "" {
+		_, err := jsutil.TryCatchCall(s.instance, "setWebSocketAutoResponse")
+		return err
+	}
+	pairClass := js.Global().Get("WebSocketRequestResponsePair")
+	if pairClass.IsUndefined() {
+		return errors.New("durableobject: WebSocketRequestResponsePair is not available")
+	}
+	_, err := jsutil.TryCatchCall(s.instance, "setWebSocketAutoResponse", pairClass.New(request, response))
+	return err
+}
+
+func toJSStrings(strs []string) js.Value {
+	arr := jsutil.NewArray(len(strs))
+	for i, s := range strs {
+		arr.SetIndex(i, s)
+	}
+	return arr
+}
+
+// WebSocketMessageHandler is implemented by the handler of a Durable Object to receive messages of hibernatable WebSockets.
+type WebSocketMessageHandler interface {
+	WebSocketMessage(ctx context.Context, ws *WebSocket, messageType websocket.MessageType, data []byte) error
+}
+
+// WebSocketCloseHandler is implemented by the handler of a Durable Object to be notified when hibernatable WebSockets are closed.
+type WebSocketCloseHandler interface {
+	WebSocketClose(ctx context.Context, ws *WebSocket, code int, reason string, wasClean bool) error
+}
+
+// WebSocketErrorHandler is implemented by the handler of a Durable Object to be notified of errors of hibernatable WebSockets.
+type WebSocketErrorHandler interface {
+	WebSocketError(ctx context.Context, ws *WebSocket, err error) error
+}
+
+// handleWebSocketEvent runs fn with the context of the WebSocket event.
+// A panic in fn is recovered, reported and returned as an error.
+func handleWebSocketEvent(wsObj, runtimeObj js.Value, fn func(ctx context.Context, ws *WebSocket) error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			recovery.Report(nil, v, debug.Stack())
+			err = recovery.Error(v)
+		}
+	}()
+	ctx := runtimecontext.New(context.Background(), wsObj, runtimeObj)
+	return fn(ctx, &WebSocket{instance: wsObj})
+}
+
+// webSocketMessage dispatches a message to WebSocketMessageHandler.
+// The message is dropped if the handler doesn't implement it.
+func (o *object) webSocketMessage(wsObj, messageObj, runtimeObj js.Value) error {
+	h, ok := o.handler.(WebSocketMessageHandler)
+	if !ok {
+		return nil
+	}
+	messageType := websocket.TextMessage
+	var data []byte
+	if messageObj.Type() == js.TypeString {
+		data = []byte(messageObj.String())
+	} else {
+		// messageObj is ArrayBuffer.
+		messageType = websocket.BinaryMessage
+		src := jsutil.Uint8ArrayClass.New(messageObj)
+		data = make([]byte, src.Length())
+		js.CopyBytesToGo(data, src)
+	}
+	return handleWebSocketEvent(wsObj, runtimeObj, func(ctx context.Context, ws *WebSocket) error {
+		return h.WebSocketMessage(ctx, ws, messageType, data)
+	})
+}
+
+// webSocketClose dispatches a close event to WebSocketCloseHandler.
+func (o *object) webSocketClose(wsObj js.Value, code int, reason string, wasClean bool, runtimeObj js.Value) error {
+	h, ok := o.handler.(WebSocketCloseHandler)
+	if !ok {
+		return nil
+	}
+	return handleWebSocketEvent(wsObj, runtimeObj, func(ctx context.Context, ws *WebSocket) error {
+		return h.WebSocketClose(ctx, ws, code, reason, wasClean)
+	})
+}
+
+// webSocketError dispatches an error to WebSocketErrorHandler.
+func (o *object) webSocketError(wsObj, errObj, runtimeObj js.Value) error {
+	h, ok := o.handler.(WebSocketErrorHandler)
+	if !ok {
+		return nil
+	}
+	wsErr := errors.New(js.Global().Get("String").Invoke(errObj).String())
+	return handleWebSocketEvent(wsObj, runtimeObj, func(ctx context.Context, ws *WebSocket) error {
+		return h.WebSocketError(ctx, ws, wsErr)
+	})
+}
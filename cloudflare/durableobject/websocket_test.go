@@ -0,0 +1,131 @@
+package durableobject
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall/js"
+	"testing"
+
+	"github.com/syumai/workers/cloudflare/websocket"
+	"github.com/syumai/workers/internal/jshttp"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// newFakeWebSocket returns a WebSocket object which stores its attachment and sent messages.
+func newFakeWebSocket() js.Value {
+	ws := jsutil.NewObject()
+	sent := jsutil.NewArray(0)
+	ws.Set("sent", sent)
+	ws.Set("send", js.FuncOf(func(_ js.Value, args []js.Value) any {
+		sent.Call("push", args[0])
+		return js.Undefined()
+	}))
+	ws.Set("serializeAttachment", js.FuncOf(func(_ js.Value, args []js.Value) any {
+		ws.Set("attachment", args[0])
+		return js.Undefined()
+	}))
+	ws.Set("deserializeAttachment", js.FuncOf(func(js.Value, []js.Value) any {
+		v := ws.Get("attachment")
+		if v.IsUndefined() {
+			return js.Null()
+		}
+		return v
+	}))
+	return ws
+}
+
+type chatRoom struct {
+	http.Handler
+	messages []string
+	closed   []int
+}
+
+func (r *chatRoom) WebSocketMessage(_ context.Context, ws *WebSocket, messageType websocket.MessageType, data []byte) error {
+	var user string
+	if _, err := ws.DeserializeAttachment(&user); err != nil {
+		return err
+	}
+	r.messages = append(r.messages, user+":"+messageType.String()+":"+string(data))
+	return ws.Send(websocket.TextMessage, []byte("ack"))
+}
+
+func (r *chatRoom) WebSocketClose(_ context.Context, _ *WebSocket, code int, _ string, _ bool) error {
+	r.closed = append(r.closed, code)
+	return nil
+}
+
+func TestObject_WebSocketEvents(t *testing.T) {
+	room := &chatRoom{Handler: http.NotFoundHandler()}
+	obj := &object{handler: room}
+	runtimeObj := newRuntimeObj(jsutil.NewObject(), newFakeState("abc"))
+
+	wsObj := newFakeWebSocket()
+	ws := &WebSocket{instance: wsObj}
+	if found, err := ws.DeserializeAttachment(new(string)); err != nil || found {
+		t.Fatalf("DeserializeAttachment() = %v, %v, want false, nil", found, err)
+	}
+	if err := ws.SerializeAttachment("alice"); err != nil {
+		t.Fatalf("SerializeAttachment() error = %v", err)
+	}
+
+	if err := obj.webSocketMessage(wsObj, js.ValueOf("hello"), runtimeObj); err != nil {
+		t.Fatalf("webSocketMessage() error = %v", err)
+	}
+	bin := jsutil.NewUint8Array(2)
+	js.CopyBytesToJS(bin, []byte("hi"))
+	if err := obj.webSocketMessage(wsObj, bin.Get("buffer"), runtimeObj); err != nil {
+		t.Fatalf("webSocketMessage() error = %v", err)
+	}
+	if err := obj.webSocketClose(wsObj, websocket.CloseGoingAway, "bye", true, runtimeObj); err != nil {
+		t.Fatalf("webSocketClose() error = %v", err)
+	}
+	// WebSocketErrorHandler is not implemented, so the error is ignored.
+	if err := obj.webSocketError(wsObj, jsutil.Error("boom"), runtimeObj); err != nil {
+		t.Fatalf("webSocketError() error = %v", err)
+	}
+
+	if len(room.messages) != 2 || room.messages[0] != "alice:text:hello" || room.messages[1] != "alice:binary:hi" {
+		t.Errorf("messages = %v", room.messages)
+	}
+	if len(room.closed) != 1 || room.closed[0] != websocket.CloseGoingAway {
+		t.Errorf("closed = %v, want [%d]", room.closed, websocket.CloseGoingAway)
+	}
+	if n := wsObj.Get("sent").Length(); n != 2 {
+		t.Errorf("len(sent) = %d, want 2", n)
+	}
+}
+
+func TestState_AcceptWebSocket_Error(t *testing.T) {
+	origPair := jsutil.MaybeWebSocketPairClass
+	jsutil.MaybeWebSocketPairClass = js.Global().Get("Function").New(`return [{}, {}];`)
+	t.Cleanup(func() { jsutil.MaybeWebSocketPairClass = origPair })
+
+	stateObj := jsutil.NewObject()
+	stateObj.Set("acceptWebSocket", js.Global().Get("Function").New(`throw new Error("too many WebSockets");`))
+	s := &State{instance: stateObj}
+
+	reader, writer := io.Pipe()
+	go io.Copy(io.Discard, reader)
+	w := &jshttp.ResponseWriter{
+		HeaderValue: http.Header{},
+		StatusCode:  http.StatusOK,
+		Writer:      writer,
+		ReadyCh:     make(chan struct{}),
+	}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Connection", "Upgrade")
+	r.Header.Set("Upgrade", "websocket")
+
+	if _, err := s.AcceptWebSocket(w, r); err == nil || !strings.Contains(err.Error(), "too many WebSockets") {
+		t.Errorf("AcceptWebSocket() error = %v, want too many WebSockets", err)
+	}
+	if w.StatusCode != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.StatusCode, http.StatusInternalServerError)
+	}
+	if !w.WebSocket.IsUndefined() {
+		t.Error("client WebSocket must not be set to the response")
+	}
+}
@@ -0,0 +1,80 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"syscall/js"
+
+	"github.com/syumai/workers"
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/recovery"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+// Handler is a function which handles an incoming email message.
+// A returned error is thrown to the runtime, and the message is rejected.
+type Handler func(ctx context.Context, msg *Message) error
+
+var handler Handler
+
+// handleEmail runs the Handler with the given message.
+// A panic in the Handler is recovered, reported and returned as an error.
+func handleEmail(messageObj, runtimeObj js.Value) (err error) {
+	if handler == nil {
+		return fmt.Errorf("email handler is not set")
+	}
+	defer func() {
+		if v := recover(); v != nil {
+			recovery.Report(nil, v, debug.Stack())
+			err = recovery.Error(v)
+		}
+	}()
+	ctx := runtimecontext.New(context.Background(), messageObj, runtimeObj)
+	return handler(ctx, newMessage(ctx, messageObj))
+}
+
+func init() {
+	handleEmailCallback := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		if len(args) != 2 {
+			panic(fmt.Errorf("invalid number of arguments given to handleEmail: %d", len(args)))
+		}
+		messageObj := args[0]
+		runtimeObj := args[1]
+		var cb js.Func
+		cb = js.FuncOf(func(_ js.Value, pArgs []js.Value) any {
+			defer cb.Release()
+			resolve := pArgs[0]
+			reject := pArgs[1]
+			go func() {
+				if err := handleEmail(messageObj, runtimeObj); err != nil {
+					reject.Invoke(jsutil.Error(err.Error()))
+					return
+				}
+				resolve.Invoke(js.Undefined())
+			}()
+			return js.Undefined()
+		})
+		return jsutil.NewPromise(cb)
+	})
+	jsutil.Binding.Set("handleEmail", handleEmailCallback)
+}
+
+// Handle sets the Handler to receive email messages routed by Email Routing.
+// NOTE: This function will block the current goroutine and is intended to be used as long as the
+// only worker's purpose is to handle email messages.
+// In case the worker has other purposes (e.g. handling HTTP requests), use HandleNonBlock instead.
+//   - The email handler must be exported from worker.mjs by workers-assets-gen with the `-email` flag.
+//   - https://developers.cloudflare.com/email-routing/email-workers/
+func Handle(h Handler) {
+	handler = h
+	workers.Ready()
+	select {}
+}
+
+// HandleNonBlock sets the Handler to receive email messages routed by Email Routing.
+// HandleNonBlock should be called before setting other blocking handlers (e.g. workers.Serve).
+//   - The email handler must be exported from worker.mjs by workers-assets-gen with the `-email` flag.
+func HandleNonBlock(h Handler) {
+	handler = h
+}
@@ -0,0 +1,193 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"syscall/js"
+	"testing"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+type fakeMessage struct {
+	obj          js.Value
+	rejectReason string
+	forwardedTo  string
+	forwardHdr   js.Value
+	replied      js.Value
+}
+
+func newFakeMessage(t *testing.T, raw string) *fakeMessage {
+	t.Helper()
+	m := &fakeMessage{}
+	obj := jsutil.NewObject()
+	obj.Set("from", "sender@example.com")
+	obj.Set("to", "recipient@example.com")
+	headers := js.Global().Get("Headers").New()
+	headers.Call("set", "Subject", "Hello")
+	obj.Set("headers", headers)
+	ua := jsutil.NewUint8Array(len(raw))
+	js.CopyBytesToJS(ua, []byte(raw))
+	obj.Set("raw", jsutil.ResponseClass.New(ua).Get("body"))
+	obj.Set("rawSize", len(raw))
+	setReject := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		m.rejectReason = args[0].String()
+		return js.Undefined()
+	})
+	forward := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		m.forwardedTo = args[0].String()
+		if len(args) > 1 {
+			m.forwardHdr = args[1]
+		}
+		return js.Global().Get("Promise").Call("resolve")
+	})
+	reply := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		m.replied = args[0]
+		return js.Global().Get("Promise").Call("resolve")
+	})
+	t.Cleanup(func() {
+		setReject.Release()
+		forward.Release()
+		reply.Release()
+	})
+	obj.Set("setReject", setReject)
+	obj.Set("forward", forward)
+	obj.Set("reply", reply)
+	m.obj = obj
+	return m
+}
+
+func newRuntimeObj(t *testing.T) js.Value {
+	t.Helper()
+	emailMessageClass := js.FuncOf(func(this js.Value, args []js.Value) any {
+		this.Set("from", args[0])
+		this.Set("to", args[1])
+		this.Set("raw", args[2])
+		return js.Undefined()
+	})
+	t.Cleanup(emailMessageClass.Release)
+	obj := jsutil.NewObject()
+	obj.Set("EmailMessage", emailMessageClass)
+	return obj
+}
+
+func runHandler(t *testing.T, h Handler, messageObj, runtimeObj js.Value) error {
+	t.Helper()
+	HandleNonBlock(h)
+	t.Cleanup(func() { handler = nil })
+	return handleEmail(messageObj, runtimeObj)
+}
+
+func TestHandleEmail(t *testing.T) {
+	const raw = "Subject: Hello\r\n\r\nbody"
+	m := newFakeMessage(t, raw)
+	var got *Message
+	var gotRaw []byte
+	err := runHandler(t, func(ctx context.Context, msg *Message) error {
+		got = msg
+		var err error
+		gotRaw, err = io.ReadAll(msg.Raw)
+		return err
+	}, m.obj, newRuntimeObj(t))
+	if err != nil {
+		t.Fatalf("handleEmail failed: %v", err)
+	}
+	if got.From != "sender@example.com" {
+		t.Errorf("From = %q, want %q", got.From, "sender@example.com")
+	}
+	if got.To != "recipient@example.com" {
+		t.Errorf("To = %q, want %q", got.To, "recipient@example.com")
+	}
+	if subject := got.Headers.Get("Subject"); subject != "Hello" {
+		t.Errorf("Headers.Get(Subject) = %q, want %q", subject, "Hello")
+	}
+	if got.RawSize != int64(len(raw)) {
+		t.Errorf("RawSize = %d, want %d", got.RawSize, len(raw))
+	}
+	if string(gotRaw) != raw {
+		t.Errorf("Raw = %q, want %q", gotRaw, raw)
+	}
+}
+
+func TestHandleEmail_Error(t *testing.T) {
+	m := newFakeMessage(t, "")
+	wantErr := errors.New("rejected")
+	err := runHandler(t, func(ctx context.Context, msg *Message) error {
+		return wantErr
+	}, m.obj, newRuntimeObj(t))
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("handleEmail() error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestHandleEmail_Panic(t *testing.T) {
+	m := newFakeMessage(t, "")
+	err := runHandler(t, func(ctx context.Context, msg *Message) error {
+		panic("broken")
+	}, m.obj, newRuntimeObj(t))
+	if err == nil {
+		t.Fatal("handleEmail() error = nil, want error")
+	}
+}
+
+func TestMessage_SetReject(t *testing.T) {
+	m := newFakeMessage(t, "")
+	err := runHandler(t, func(ctx context.Context, msg *Message) error {
+		msg.SetReject("unknown address")
+		return nil
+	}, m.obj, newRuntimeObj(t))
+	if err != nil {
+		t.Fatalf("handleEmail failed: %v", err)
+	}
+	if m.rejectReason != "unknown address" {
+		t.Errorf("reject reason = %q, want %q", m.rejectReason, "unknown address")
+	}
+}
+
+func TestMessage_Forward(t *testing.T) {
+	m := newFakeMessage(t, "")
+	err := runHandler(t, func(ctx context.Context, msg *Message) error {
+		return msg.Forward("inbox@example.com", http.Header{"X-Forwarded-By": {"workers"}})
+	}, m.obj, newRuntimeObj(t))
+	if err != nil {
+		t.Fatalf("handleEmail failed: %v", err)
+	}
+	if m.forwardedTo != "inbox@example.com" {
+		t.Errorf("forwarded to = %q, want %q", m.forwardedTo, "inbox@example.com")
+	}
+	if got := m.forwardHdr.Call("get", "X-Forwarded-By").String(); got != "workers" {
+		t.Errorf("forwarded header = %q, want %q", got, "workers")
+	}
+}
+
+func TestMessage_Reply(t *testing.T) {
+	m := newFakeMessage(t, "")
+	err := runHandler(t, func(ctx context.Context, msg *Message) error {
+		return msg.Reply(&EmailMessage{
+			From: msg.To,
+			To:   msg.From,
+			Raw:  []byte("Subject: Re: Hello\r\n\r\nthanks"),
+		})
+	}, m.obj, newRuntimeObj(t))
+	if err != nil {
+		t.Fatalf("handleEmail failed: %v", err)
+	}
+	if got := m.replied.Get("from").String(); got != "recipient@example.com" {
+		t.Errorf("reply from = %q, want %q", got, "recipient@example.com")
+	}
+	if got := m.replied.Get("to").String(); got != "sender@example.com" {
+		t.Errorf("reply to = %q, want %q", got, "sender@example.com")
+	}
+}
+
+func TestMessage_Reply_NoEmailMessage(t *testing.T) {
+	m := newFakeMessage(t, "")
+	err := runHandler(t, func(ctx context.Context, msg *Message) error {
+		return msg.Reply(&EmailMessage{})
+	}, m.obj, jsutil.NewObject())
+	if !errors.Is(err, ErrEmailMessageNotAvailable) {
+		t.Fatalf("handleEmail() error = %v, want %v", err, ErrEmailMessageNotAvailable)
+	}
+}
@@ -0,0 +1,290 @@
+package email
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Mail is a MIME email message composed of headers, text and HTML bodies, and attachments.
+// Mail is converted into the raw message by Bytes, or into the EmailMessage to be sent by EmailMessage.
+type Mail struct {
+	From    *mail.Address
+	To      []*mail.Address
+	Cc      []*mail.Address
+	ReplyTo *mail.Address
+	Subject string
+	// Header holds additional headers (e.g. In-Reply-To). Headers set here override the generated ones.
+	// Date and Message-ID are generated if they are not set. Values containing CR or LF are rejected by Bytes.
+	Header textproto.MIMEHeader
+	// Text is the plain text body.
+	Text string
+	// HTML is the HTML body. If both Text and HTML are set, they are sent as multipart/alternative.
+	HTML        string
+	Attachments []*Attachment
+}
+
+// Attachment is a file attached to Mail.
+type Attachment struct {
+	Filename string
+	// ContentType is the media type of Data. Default is application/octet-stream.
+	ContentType string
+	// ContentID makes the attachment inline and referable by `cid:` URL from the HTML body. (optional)
+	ContentID string
+	Data      []byte
+}
+
+// EmailMessage converts m into an EmailMessage sent to rcptTo.
+// The envelope From address is m.From.
+func (m *Mail) EmailMessage(rcptTo string) (*EmailMessage, error) {
+	raw, err := m.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return &EmailMessage{
+		From: m.From.Address,
+		To:   rcptTo,
+		Raw:  raw,
+	}, nil
+}
+
+// Bytes returns the raw MIME message of m.
+func (m *Mail) Bytes() ([]byte, error) {
+	if m.From == nil {
+		return nil, errors.New("email: From is required")
+	}
+	h := textproto.MIMEHeader{}
+	h.Set("From", m.From.String())
+	if len(m.To) > 0 {
+		h.Set("To", formatAddressList(m.To))
+	}
+	if len(m.Cc) > 0 {
+		h.Set("Cc", formatAddressList(m.Cc))
+	}
+	if m.ReplyTo != nil {
+		h.Set("Reply-To", m.ReplyTo.String())
+	}
+	if m.Subject != "" {
+		h.Set("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	}
+	h.Set("Date", time.Now().Format(time.RFC1123Z))
+	messageID, err := newMessageID(m.From.Address)
+	if err != nil {
+		return nil, err
+	}
+	h.Set("Message-Id", messageID)
+	for k, vs := range m.Header {
+		if err := checkHeader(k, vs...); err != nil {
+			return nil, err
+		}
+		h[textproto.CanonicalMIMEHeaderKey(k)] = vs
+	}
+	h.Set("Mime-Version", "1.0")
+
+	var body bytes.Buffer
+	bodyHeader, err := m.writeBody(&body)
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range bodyHeader {
+		h[k] = vs
+	}
+
+	var buf bytes.Buffer
+	writeHeader(&buf, h)
+	buf.Write(body.Bytes())
+	return buf.Bytes(), nil
+}
+
+// writeBody writes the body of m into w, and returns the headers describing the body.
+func (m *Mail) writeBody(w io.Writer) (textproto.MIMEHeader, error) {
+	if len(m.Attachments) == 0 {
+		return m.writeContent(w)
+	}
+	mw := multipart.NewWriter(w)
+	if m.Text != "" || m.HTML != "" {
+		var content bytes.Buffer
+		h, err := m.writeContent(&content)
+		if err != nil {
+			return nil, err
+		}
+		pw, err := mw.CreatePart(h)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := pw.Write(content.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	for _, a := range m.Attachments {
+		if err := a.write(mw); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return textproto.MIMEHeader{
+		"Content-Type": {mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": mw.Boundary()})},
+	}, nil
+}
+
+// writeContent writes the text and HTML bodies of m into w, and returns the headers describing them.
+func (m *Mail) writeContent(w io.Writer) (textproto.MIMEHeader, error) {
+	if m.Text == "" || m.HTML == "" {
+		contentType, content := "text/plain; charset=utf-8", m.Text
+		if m.HTML != "" {
+			contentType, content = "text/html; charset=utf-8", m.HTML
+		}
+		if err := writeQuotedPrintable(w, content); err != nil {
+			return nil, err
+		}
+		return textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		}, nil
+	}
+	mw := multipart.NewWriter(w)
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.HTML},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(pw, part.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return textproto.MIMEHeader{
+		"Content-Type": {mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": mw.Boundary()})},
+	}, nil
+}
+
+func (a *Attachment) write(mw *multipart.Writer) error {
+	if err := checkHeader("Content-Type", a.ContentType); err != nil {
+		return err
+	}
+	if err := checkHeader("Content-Id", a.ContentID); err != nil {
+		return err
+	}
+	contentType := a.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	disposition := "attachment"
+	h := textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"base64"},
+	}
+	if a.ContentID != "" {
+		disposition = "inline"
+		h.Set("Content-Id", "<"+a.ContentID+">")
+	}
+	params := map[string]string{}
+	if a.Filename != "" {
+		params["filename"] = a.Filename
+	}
+	h.Set("Content-Disposition", mime.FormatMediaType(disposition, params))
+	pw, err := mw.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	return writeBase64(pw, a.Data)
+}
+
+// maxLineLength is the maximum length of encoded lines defined in RFC 2045.
+const maxLineLength = 76
+
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	bw := bufio.NewWriter(w)
+	for len(encoded) > 0 {
+		n := min(len(encoded), maxLineLength)
+		bw.WriteString(encoded[:n])
+		bw.WriteString("\r\n")
+		encoded = encoded[n:]
+	}
+	return bw.Flush()
+}
+
+func writeQuotedPrintable(w io.Writer, s string) error {
+	qw := quotedprintable.NewWriter(w)
+	if _, err := io.WriteString(qw, s); err != nil {
+		return err
+	}
+	return qw.Close()
+}
+
+// checkHeader returns an error if the header can't be written as is.
+// CR and LF are rejected to prevent injecting other headers or the body.
+func checkHeader(key string, values ...string) error {
+	if key == "" || strings.ContainsAny(key, "\r\n: \t") {
+		return fmt.Errorf("email: invalid header key: %q", key)
+	}
+	for _, v := range values {
+		if strings.ContainsAny(v, "\r\n") {
+			return fmt.Errorf("email: invalid header value of %s: must not contain CR or LF", key)
+		}
+	}
+	return nil
+}
+
+// writeHeader writes h into buf in sorted order, followed by an empty line.
+func writeHeader(buf *bytes.Buffer, h textproto.MIMEHeader) {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range h[k] {
+			fmt.Fprintf(buf, "%s: %s\r\n", k, v)
+		}
+	}
+	buf.WriteString("\r\n")
+}
+
+func formatAddressList(addrs []*mail.Address) string {
+	s := make([]string, len(addrs))
+	for i, addr := range addrs {
+		s[i] = addr.String()
+	}
+	return strings.Join(s, ", ")
+}
+
+// newMessageID generates a Message-ID on the domain of the from address.
+func newMessageID(from string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = from[i+1:]
+	}
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(b), domain), nil
+}
@@ -0,0 +1,171 @@
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+func readPart(t *testing.T, p *multipart.Part) string {
+	t.Helper()
+	b, err := io.ReadAll(p)
+	if err != nil {
+		t.Fatalf("failed to read part: %v", err)
+	}
+	return string(b)
+}
+
+func TestMail_Bytes_Text(t *testing.T) {
+	m := &Mail{
+		From:    &mail.Address{Name: "Sender", Address: "sender@example.com"},
+		To:      []*mail.Address{{Address: "recipient@example.com"}},
+		Subject: "こんにちは",
+		Text:    "Hello, world!",
+	}
+	raw, err := m.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes failed: %v", err)
+	}
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ReadMessage failed: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("failed to decode Subject: %v", err)
+	}
+	if subject != m.Subject {
+		t.Errorf("Subject = %q, want %q", subject, m.Subject)
+	}
+	from, err := msg.Header.AddressList("From")
+	if err != nil {
+		t.Fatalf("failed to parse From: %v", err)
+	}
+	if from[0].String() != m.From.String() {
+		t.Errorf("From = %v, want %v", from[0], m.From)
+	}
+	if !strings.HasSuffix(msg.Header.Get("Message-Id"), "@example.com>") {
+		t.Errorf("Message-Id = %q, want a value on example.com", msg.Header.Get("Message-Id"))
+	}
+	if _, err := msg.Header.Date(); err != nil {
+		t.Errorf("failed to parse Date: %v", err)
+	}
+	if got := msg.Header.Get("Content-Type"); got != "text/plain; charset=utf-8" {
+		t.Errorf("Content-Type = %q, want %q", got, "text/plain; charset=utf-8")
+	}
+	body, _ := io.ReadAll(msg.Body)
+	if string(body) != m.Text {
+		t.Errorf("body = %q, want %q", body, m.Text)
+	}
+}
+
+func TestMail_Bytes_Multipart(t *testing.T) {
+	m := &Mail{
+		From:   &mail.Address{Address: "sender@example.com"},
+		To:     []*mail.Address{{Address: "a@example.com"}, {Address: "b@example.com"}},
+		Header: textproto.MIMEHeader{"In-Reply-To": {"<original@example.com>"}},
+		Text:   "Hello",
+		HTML:   "<p>Hello</p>",
+		Attachments: []*Attachment{
+			{Filename: "report.csv", ContentType: "text/csv", Data: []byte("a,b\n1,2\n")},
+		},
+	}
+	raw, err := m.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes failed: %v", err)
+	}
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ReadMessage failed: %v", err)
+	}
+	if got := msg.Header.Get("In-Reply-To"); got != "<original@example.com>" {
+		t.Errorf("In-Reply-To = %q, want %q", got, "<original@example.com>")
+	}
+	to, err := msg.Header.AddressList("To")
+	if err != nil || len(to) != 2 {
+		t.Fatalf("To = %v, %v, want 2 addresses", to, err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Content-Type = %q, want multipart/mixed", msg.Header.Get("Content-Type"))
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+
+	alt, err := mr.NextPart()
+	if err != nil {
+		t.Fatalf("failed to read alternative part: %v", err)
+	}
+	mediaType, params, _ = mime.ParseMediaType(alt.Header.Get("Content-Type"))
+	if mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, want multipart/alternative", mediaType)
+	}
+	ar := multipart.NewReader(alt, params["boundary"])
+	for _, want := range []string{m.Text, m.HTML} {
+		p, err := ar.NextPart()
+		if err != nil {
+			t.Fatalf("failed to read body part: %v", err)
+		}
+		if got := readPart(t, p); got != want {
+			t.Errorf("body part = %q, want %q", got, want)
+		}
+	}
+
+	att, err := mr.NextPart()
+	if err != nil {
+		t.Fatalf("failed to read attachment: %v", err)
+	}
+	if att.FileName() != "report.csv" {
+		t.Errorf("FileName() = %q, want %q", att.FileName(), "report.csv")
+	}
+	data, err := base64.StdEncoding.DecodeString(readPart(t, att))
+	if err != nil {
+		t.Fatalf("failed to decode attachment: %v", err)
+	}
+	if got := string(data); got != string(m.Attachments[0].Data) {
+		t.Errorf("attachment = %q, want %q", got, m.Attachments[0].Data)
+	}
+	if _, err := mr.NextPart(); err != io.EOF {
+		t.Errorf("NextPart() error = %v, want io.EOF", err)
+	}
+}
+
+func TestMail_Bytes_NoFrom(t *testing.T) {
+	if _, err := (&Mail{Text: "Hello"}).Bytes(); err == nil {
+		t.Fatal("Bytes() error = nil, want error")
+	}
+}
+
+func TestMail_Bytes_HeaderInjection(t *testing.T) {
+	from := &mail.Address{Address: "sender@example.com"}
+	tests := map[string]*Mail{
+		"LF in value": {
+			From:   from,
+			Header: textproto.MIMEHeader{"X-Custom": {"value\nBcc: victim@example.com"}},
+		},
+		"CRLF in value": {
+			From:   from,
+			Header: textproto.MIMEHeader{"X-Custom": {"value\r\n\r\nsmuggled body"}},
+		},
+		"CR in key": {
+			From:   from,
+			Header: textproto.MIMEHeader{"X-Custom\rBcc": {"victim@example.com"}},
+		},
+		"LF in attachment content type": {
+			From:        from,
+			Attachments: []*Attachment{{ContentType: "text/plain\nX-Injected: 1"}},
+		},
+	}
+	for name, m := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := m.Bytes(); err == nil {
+				t.Error("Bytes() error = nil, want error")
+			}
+		})
+	}
+}
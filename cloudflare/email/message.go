@@ -0,0 +1,104 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/mail"
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
+	"github.com/syumai/workers/internal/jshttp"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// ErrEmailMessageNotAvailable is returned when EmailMessage of `cloudflare:email` is not available on the runtime.
+var ErrEmailMessageNotAvailable = errors.New("email: EmailMessage is not available")
+
+// Message represents an incoming email message received by an Email Worker.
+//   - https://developers.cloudflare.com/email-routing/email-workers/runtime-api/#forwardableemailmessage-definition
+type Message struct {
+	instance js.Value
+	// emailMessageClass is EmailMessage class of `cloudflare:email` used to reply.
+	emailMessageClass js.Value
+
+	// From is the envelope From address.
+	From string
+	// To is the envelope To address.
+	To string
+	// Headers is the headers of the message.
+	Headers mail.Header
+	// Raw is the raw MIME message including headers. This can be parsed by mail.ReadMessage.
+	// Raw can be read only once.
+	Raw io.Reader
+	// RawSize is the size of Raw in bytes.
+	RawSize int64
+}
+
+func newMessage(ctx context.Context, v js.Value) *Message {
+	emailMessageClass, err := cfruntimecontext.GetRuntimeContextValueFromContext(ctx, "EmailMessage")
+	if err != nil {
+		emailMessageClass = js.Undefined()
+	}
+	return &Message{
+		instance:          v,
+		emailMessageClass: emailMessageClass,
+		From:              v.Get("from").String(),
+		To:                v.Get("to").String(),
+		Headers:           mail.Header(jshttp.ToHeader(v.Get("headers"))),
+		Raw:               jsutil.ConvertReadableStreamToReadCloser(v.Get("raw")),
+		RawSize:           int64(jsutil.MaybeInt(v.Get("rawSize"))),
+	}
+}
+
+// SetReject rejects the message with the reason.
+// The reason is sent to the sender in the bounce message.
+func (m *Message) SetReject(reason string) {
+	m.instance.Call("setReject", reason)
+}
+
+// Forward forwards the message to rcptTo which must be a verified destination address.
+//   - headers are added to the forwarded message. Only `X-` prefixed headers are allowed. (optional)
+func (m *Message) Forward(rcptTo string, headers http.Header) error {
+	var p js.Value
+	if headers == nil {
+		p = m.instance.Call("forward", rcptTo)
+	} else {
+		p = m.instance.Call("forward", rcptTo, jshttp.ToJSHeader(headers))
+	}
+	_, err := jsutil.AwaitPromise(p)
+	return err
+}
+
+// Reply replies to the message with msg.
+//   - msg.To must be the sender of the message, and msg.Raw must have `In-Reply-To` header of the Message-ID of the message.
+func (m *Message) Reply(msg *EmailMessage) error {
+	v, err := msg.toJS(m.emailMessageClass)
+	if err != nil {
+		return err
+	}
+	_, err = jsutil.AwaitPromise(m.instance.Call("reply", v))
+	return err
+}
+
+// EmailMessage represents an email message to be sent.
+//   - https://developers.cloudflare.com/email-routing/email-workers/send-email-workers/
+type EmailMessage struct {
+	// From is the envelope From address.
+	From string
+	// To is the envelope To address.
+	To string
+	// Raw is the raw MIME message including headers.
+	Raw []byte
+}
+
+func (m *EmailMessage) toJS(emailMessageClass js.Value) (js.Value, error) {
+	if emailMessageClass.IsUndefined() {
+		return js.Value{}, ErrEmailMessageNotAvailable
+	}
+	ua := jsutil.NewUint8Array(len(m.Raw))
+	js.CopyBytesToJS(ua, m.Raw)
+	raw := jsutil.ResponseClass.New(ua).Get("body")
+	return emailMessageClass.New(m.From, m.To, raw), nil
+}
@@ -0,0 +1,49 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// Sender sends email messages through the send_email binding.
+//   - https://developers.cloudflare.com/email-routing/email-workers/send-email-workers/
+type Sender struct {
+	instance          js.Value
+	emailMessageClass js.Value
+}
+
+// NewSender returns a Sender for the send_email binding.
+// varName is the name of the binding defined in wrangler.toml.
+//   - https://developers.cloudflare.com/email-routing/email-workers/send-email-workers/#types-of-bindings
+//   - On an instance shared across events (workers-assets-gen -instance=shared), this function fails since the event is unknown. Use NewSenderFromContext instead.
+func NewSender(varName string) (*Sender, error) {
+	return NewSenderFromContext(context.Background(), varName)
+}
+
+// NewSenderFromContext returns a Sender for the send_email binding resolved from the runtime context attached to ctx.
+// If the runtime context is not attached to ctx, this function behaves the same as NewSender.
+func NewSenderFromContext(ctx context.Context, varName string) (*Sender, error) {
+	inst := cfruntimecontext.MustGetRuntimeContextEnvFromContext(ctx).Get(varName)
+	if inst.IsUndefined() {
+		return nil, fmt.Errorf("%s is undefined", varName)
+	}
+	emailMessageClass, err := cfruntimecontext.GetRuntimeContextValueFromContext(ctx, "EmailMessage")
+	if err != nil {
+		emailMessageClass = js.Undefined()
+	}
+	return &Sender{instance: inst, emailMessageClass: emailMessageClass}, nil
+}
+
+// Send sends msg. msg.To must be allowed by the destination settings of the binding.
+func (s *Sender) Send(msg *EmailMessage) error {
+	v, err := msg.toJS(s.emailMessageClass)
+	if err != nil {
+		return err
+	}
+	_, err = jsutil.AwaitPromise(s.instance.Call("send", v))
+	return err
+}
@@ -0,0 +1,58 @@
+package email
+
+import (
+	"context"
+	"net/mail"
+	"syscall/js"
+	"testing"
+
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+func TestSender_Send(t *testing.T) {
+	var sent js.Value
+	send := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		sent = args[0]
+		return js.Global().Get("Promise").Call("resolve")
+	})
+	defer send.Release()
+	binding := jsutil.NewObject()
+	binding.Set("send", send)
+	env := jsutil.NewObject()
+	env.Set("SEND_EMAIL", binding)
+	runtimeObj := newRuntimeObj(t)
+	runtimeObj.Set("env", env)
+	ctx := runtimecontext.WithRuntimeObj(context.Background(), runtimeObj)
+
+	s, err := NewSenderFromContext(ctx, "SEND_EMAIL")
+	if err != nil {
+		t.Fatalf("NewSenderFromContext failed: %v", err)
+	}
+	msg, err := (&Mail{
+		From: &mail.Address{Address: "sender@example.com"},
+		To:   []*mail.Address{{Address: "recipient@example.com"}},
+		Text: "Hello",
+	}).EmailMessage("recipient@example.com")
+	if err != nil {
+		t.Fatalf("EmailMessage failed: %v", err)
+	}
+	if err := s.Send(msg); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if got := sent.Get("from").String(); got != "sender@example.com" {
+		t.Errorf("from = %q, want %q", got, "sender@example.com")
+	}
+	if got := sent.Get("to").String(); got != "recipient@example.com" {
+		t.Errorf("to = %q, want %q", got, "recipient@example.com")
+	}
+}
+
+func TestNewSenderFromContext_Undefined(t *testing.T) {
+	runtimeObj := newRuntimeObj(t)
+	runtimeObj.Set("env", jsutil.NewObject())
+	ctx := runtimecontext.WithRuntimeObj(context.Background(), runtimeObj)
+	if _, err := NewSenderFromContext(ctx, "SEND_EMAIL"); err == nil {
+		t.Fatal("NewSenderFromContext() error = nil, want error")
+	}
+}
@@ -1,16 +1,27 @@
 package cloudflare
 
 import (
+	"context"
 	"syscall/js"
 
 	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/runtimecontext"
 )
 
 // Getenv gets a value of an environment variable.
 //   - https://developers.cloudflare.com/workers/platform/environment-variables/
 //   - This function panics when a runtime context is not found.
+//   - On an instance shared across events (workers-assets-gen -instance=shared), this function fails since the event is unknown. Use GetenvContext instead.
 func Getenv(name string) string {
-	if val := cfruntimecontext.MustGetRuntimeContextEnv().Get(name); !val.IsUndefined() {
+	return GetenvContext(context.Background(), name)
+}
+
+// GetenvContext gets a value of an environment variable from the runtime context attached to ctx.
+//   - If the runtime context is not attached to ctx, this function behaves the same as Getenv.
+//   - This function panics when a runtime context is not found.
+func GetenvContext(ctx context.Context, name string) string {
+	if val := cfruntimecontext.MustGetRuntimeContextEnvFromContext(ctx).Get(name); !val.IsUndefined() {
 		return val.String()
 	}
 	return ""
@@ -19,6 +30,26 @@ func Getenv(name string) string {
 // GetBinding gets a value of an environment binding.
 //   - https://developers.cloudflare.com/workers/platform/bindings/about-service-bindings/
 //   - This function panics when a runtime context is not found.
+//   - On an instance shared across events (workers-assets-gen -instance=shared), this function fails since the event is unknown. Use GetBindingContext instead.
 func GetBinding(name string) js.Value {
-	return cfruntimecontext.MustGetRuntimeContextEnv().Get(name)
+	return GetBindingContext(context.Background(), name)
+}
+
+// GetBindingContext gets a value of an environment binding from the runtime context attached to ctx.
+//   - If the runtime context is not attached to ctx, this function behaves the same as GetBinding.
+//   - This function panics when a runtime context is not found.
+func GetBindingContext(ctx context.Context, name string) js.Value {
+	return cfruntimecontext.MustGetRuntimeContextEnvFromContext(ctx).Get(name)
+}
+
+// WithEnv returns a copy of ctx whose environment variables and bindings are resolved from env.
+//   - Other values of the runtime context attached to ctx are kept.
+//   - This is useful to pass bindings explicitly, e.g. in tests.
+func WithEnv(ctx context.Context, env js.Value) context.Context {
+	runtimeObj := jsutil.NewObject()
+	if v, ok := runtimecontext.ExtractRuntimeObj(ctx); ok && !v.IsUndefined() && !v.IsNull() {
+		jsutil.ObjectClass.Call("assign", runtimeObj, v)
+	}
+	runtimeObj.Set("env", env)
+	return runtimecontext.WithRuntimeObj(ctx, runtimeObj)
 }
@@ -0,0 +1,47 @@
+package cloudflare
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+func TestGetenvContext(t *testing.T) {
+	env := jsutil.NewObject()
+	env.Set("GREETING", "hello")
+	ctx := WithEnv(context.Background(), env)
+
+	if got := GetenvContext(ctx, "GREETING"); got != "hello" {
+		t.Errorf("GetenvContext() = %q, want %q", got, "hello")
+	}
+	if got := GetenvContext(ctx, "MISSING"); got != "" {
+		t.Errorf("GetenvContext() = %q, want empty string", got)
+	}
+}
+
+func TestWithEnv_KeepsRuntimeContextValues(t *testing.T) {
+	runtimeObj := jsutil.NewObject()
+	runtimeObj.Set("ctx", "execution-context")
+	ctx := runtimecontext.New(context.Background(), js.Undefined(), runtimeObj)
+
+	env := jsutil.NewObject()
+	env.Set("BUCKET", "bucket-binding")
+	ctx = WithEnv(ctx, env)
+
+	got, ok := runtimecontext.ExtractRuntimeObj(ctx)
+	if !ok {
+		t.Fatal("runtime context object was not found")
+	}
+	if v := got.Get("ctx").String(); v != "execution-context" {
+		t.Errorf("ctx = %q, want %q", v, "execution-context")
+	}
+	if v := GetBindingContext(ctx, "BUCKET").String(); v != "bucket-binding" {
+		t.Errorf("GetBindingContext() = %q, want %q", v, "bucket-binding")
+	}
+	if v := runtimeObj.Get("env"); !v.IsUndefined() {
+		t.Errorf("original runtime context object must not be modified, got env = %v", v)
+	}
+}
@@ -11,20 +11,31 @@ import (
 
 // fetch is a function that reproduces cloudflare fetch.
 // Docs: https://developers.cloudflare.com/workers/runtime-apis/fetch/
+//   - The request is aborted when the context of req is done before the response is returned.
 func fetch(namespace js.Value, req *http.Request, init *RequestInit) (*http.Response, error) {
 	if namespace.IsUndefined() {
 		return nil, errors.New("fetch function not found")
 	}
+	ctx := req.Context()
+	initObj := init.ToJS()
+	if ctx.Done() != nil {
+		if initObj.IsUndefined() {
+			initObj = jsutil.NewObject()
+		}
+		signal, stop := jsutil.NewAbortSignal(ctx)
+		defer stop()
+		initObj.Set("signal", signal)
+	}
 	promise := namespace.Call("fetch",
 		// The Request object to fetch.
 		// Docs: https://developers.cloudflare.com/workers/runtime-apis/request
 		jshttp.ToJSRequest(req),
 		// The content of the request.
 		// Docs: https://developers.cloudflare.com/workers/runtime-apis/request#requestinit
-		init.ToJS(),
+		initObj,
 	)
 
-	jsRes, err := jsutil.AwaitPromise(promise)
+	jsRes, err := jsutil.AwaitPromiseContext(ctx, promise)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package cloudflare
 
 import (
+	"context"
 	"syscall/js"
 
 	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
@@ -10,8 +11,15 @@ import (
 // WaitUntil extends the lifetime of the "fetch" event.
 // It accepts an asynchronous task which the Workers runtime will execute before the handler terminates but without blocking the response.
 // see: https://developers.cloudflare.com/workers/runtime-apis/fetch-event/#waituntil
+//   - On an instance shared across events (workers-assets-gen -instance=shared), this function fails since the event is unknown. Use WaitUntilContext instead.
 func WaitUntil(task func()) {
-	exCtx := cfruntimecontext.MustGetExecutionContext()
+	WaitUntilContext(context.Background(), task)
+}
+
+// WaitUntilContext extends the lifetime of the event which ctx belongs to.
+//   - If the runtime context is not attached to ctx, this function behaves the same as WaitUntil.
+func WaitUntilContext(ctx context.Context, task func()) {
+	exCtx := cfruntimecontext.MustGetExecutionContextFromContext(ctx)
 	exCtx.Call("waitUntil", jsutil.NewPromise(js.FuncOf(func(this js.Value, pArgs []js.Value) any {
 		resolve := pArgs[0]
 		go func() {
@@ -25,8 +33,15 @@ func WaitUntil(task func()) {
 // PassThroughOnException prevents a runtime error response when the Worker script throws an unhandled exception.
 // Instead, the request forwards to the origin server as if it had not gone through the worker.
 // see: https://developers.cloudflare.com/workers/runtime-apis/fetch-event/#passthroughonexception
+//   - On an instance shared across events (workers-assets-gen -instance=shared), this function fails since the event is unknown. Use PassThroughOnExceptionContext instead.
 func PassThroughOnException() {
-	exCtx := cfruntimecontext.MustGetExecutionContext()
+	PassThroughOnExceptionContext(context.Background())
+}
+
+// PassThroughOnExceptionContext calls PassThroughOnException for the event which ctx belongs to.
+//   - If the runtime context is not attached to ctx, this function behaves the same as PassThroughOnException.
+func PassThroughOnExceptionContext(ctx context.Context) {
+	exCtx := cfruntimecontext.MustGetExecutionContextFromContext(ctx)
 	jsutil.AwaitPromise(jsutil.NewPromise(js.FuncOf(func(this js.Value, pArgs []js.Value) any {
 		resolve := pArgs[0]
 		go func() {
@@ -1,10 +1,12 @@
 package cfruntimecontext
 
 import (
+	"context"
 	"errors"
 	"syscall/js"
 
 	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/runtimecontext"
 )
 
 /**
@@ -45,10 +47,60 @@ func MustGetRuntimeContextValue(key string) js.Value {
 
 var ErrValueNotFound = errors.New("execution context value for specified key not found")
 
+// ErrRuntimeContextNotAttached is returned when a per-event value is requested without a runtime context attached to ctx
+// while the instance is shared across events.
+var ErrRuntimeContextNotAttached = errors.New("runtime context is not attached to context: on a shared instance, per-event values (env, ctx) are only available from the context of the event (e.g. req.Context())")
+
+// perEventKeys holds the keys of RuntimeContext whose values differ for each event.
+var perEventKeys = map[string]bool{
+	"env": true,
+	"ctx": true,
+}
+
 // GetRuntimeContextValue gets value for specified key from RuntimeContext.
 // - if the value is undefined, return error.
 func GetRuntimeContextValue(key string) (js.Value, error) {
-	runtimeObj := jsutil.RuntimeContext
+	return GetRuntimeContextValueFromContext(context.Background(), key)
+}
+
+// MustGetRuntimeContextEnvFromContext gets object which holds environment variables from RuntimeContext attached to ctx.
+// - if RuntimeContext is not attached to ctx, the one bound to the instance is used.
+func MustGetRuntimeContextEnvFromContext(ctx context.Context) js.Value {
+	return MustGetRuntimeContextValueFromContext(ctx, "env")
+}
+
+// MustGetExecutionContextFromContext gets ExecutionContext object from RuntimeContext attached to ctx.
+// - if RuntimeContext is not attached to ctx, the one bound to the instance is used.
+func MustGetExecutionContextFromContext(ctx context.Context) js.Value {
+	return MustGetRuntimeContextValueFromContext(ctx, "ctx")
+}
+
+// MustGetRuntimeContextValueFromContext gets value for specified key from RuntimeContext attached to ctx.
+// - if the value is undefined, this function panics.
+func MustGetRuntimeContextValueFromContext(ctx context.Context, key string) js.Value {
+	val, err := GetRuntimeContextValueFromContext(ctx, key)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+// GetRuntimeContextValueFromContext gets value for specified key from RuntimeContext attached to ctx.
+// - if RuntimeContext is not attached to ctx, the one bound to the instance is used.
+// - if the instance is shared across events, the per-event values (env, ctx) of the instance are not used and ErrRuntimeContextNotAttached is returned.
+// - if the value is undefined, return error.
+func GetRuntimeContextValueFromContext(ctx context.Context, key string) (js.Value, error) {
+	runtimeObj, ok := runtimecontext.ExtractRuntimeObj(ctx)
+	if !ok {
+		// The runtime context bound to a shared instance belongs to the event which booted it.
+		if perEventKeys[key] && jsutil.IsInstanceReused() {
+			return js.Value{}, ErrRuntimeContextNotAttached
+		}
+		runtimeObj = jsutil.RuntimeContext
+	}
+	if runtimeObj.IsUndefined() || runtimeObj.IsNull() {
+		return js.Value{}, ErrValueNotFound
+	}
 	v := runtimeObj.Get(key)
 	if v.IsUndefined() {
 		return js.Value{}, ErrValueNotFound
@@ -0,0 +1,99 @@
+package cfruntimecontext
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+func setInstanceRuntimeContext(t *testing.T, reuseInstance bool) {
+	t.Helper()
+	instanceObj := jsutil.NewObject()
+	instanceObj.Set("env", "instance-env")
+	instanceObj.Set("connect", "instance-connect")
+	instanceObj.Set("reuseInstance", reuseInstance)
+	orig := jsutil.RuntimeContext
+	jsutil.RuntimeContext = instanceObj
+	t.Cleanup(func() { jsutil.RuntimeContext = orig })
+}
+
+func TestGetRuntimeContextValueFromContext(t *testing.T) {
+	eventObj := jsutil.NewObject()
+	eventObj.Set("env", "event-env")
+	eventCtx := runtimecontext.New(context.Background(), jsutil.NewObject(), eventObj)
+
+	tests := map[string]struct {
+		reuseInstance bool
+		ctx           context.Context
+		key           string
+		want          string
+		wantErr       error
+	}{
+		"per-event instance falls back to instance env": {
+			ctx:  context.Background(),
+			key:  "env",
+			want: "instance-env",
+		},
+		"shared instance uses attached env": {
+			reuseInstance: true,
+			ctx:           eventCtx,
+			key:           "env",
+			want:          "event-env",
+		},
+		"shared instance rejects env without runtime context": {
+			reuseInstance: true,
+			ctx:           context.Background(),
+			key:           "env",
+			wantErr:       ErrRuntimeContextNotAttached,
+		},
+		"shared instance rejects ctx without runtime context": {
+			reuseInstance: true,
+			ctx:           context.Background(),
+			key:           "ctx",
+			wantErr:       ErrRuntimeContextNotAttached,
+		},
+		"shared instance falls back for non per-event value": {
+			reuseInstance: true,
+			ctx:           context.Background(),
+			key:           "connect",
+			want:          "instance-connect",
+		},
+		"missing value": {
+			ctx:     context.Background(),
+			key:     "ctx",
+			wantErr: ErrValueNotFound,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			setInstanceRuntimeContext(t, tc.reuseInstance)
+			got, err := GetRuntimeContextValueFromContext(tc.ctx, tc.key)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.String() != tc.want {
+				t.Errorf("value = %q, want %q", got.String(), tc.want)
+			}
+		})
+	}
+}
+
+func TestMustGetRuntimeContextEnv_SharedInstancePanics(t *testing.T) {
+	setInstanceRuntimeContext(t, true)
+	defer func() {
+		v := recover()
+		if err, ok := v.(error); !ok || !errors.Is(err, ErrRuntimeContextNotAttached) {
+			t.Errorf("recovered %v, want %v", v, ErrRuntimeContextNotAttached)
+		}
+	}()
+	MustGetRuntimeContextEnv()
+}
@@ -1,9 +1,12 @@
 package kv
 
 import (
+	"encoding/json"
+	"fmt"
 	"io"
 	"syscall/js"
 
+	"github.com/syumai/workers/cloudflare/codec"
 	"github.com/syumai/workers/internal/jsutil"
 )
 
@@ -46,3 +49,149 @@ func (ns *Namespace) GetReader(key string, opts *GetOptions) (io.Reader, error)
 	}
 	return jsutil.ConvertReadableStreamToReadCloser(v), nil
 }
+
+// toMetadata converts JavaScript side's metadata into *codec.Value.
+//   - if the metadata is null or undefined, returns nil.
+func toMetadata(v js.Value) *codec.Value {
+	if v.IsUndefined() || v.IsNull() {
+		return nil
+	}
+	return codec.NewValue(v, codec.JSON)
+}
+
+// GetStringWithMetadata gets string value and its metadata by the specified key.
+//   - metadata is nil if the value has no metadata. It can be decoded into a Go value with Decode.
+//   - ok is false if the key is not found, so a missing key can be told apart from an empty string.
+//   - if a network error happens, returns error.
+func (ns *Namespace) GetStringWithMetadata(key string, opts *GetOptions) (value string, metadata *codec.Value, ok bool, err error) {
+	p := ns.instance.Call("getWithMetadata", key, opts.toJS("text"))
+	v, err := jsutil.AwaitPromise(p)
+	if err != nil {
+		return "", nil, false, err
+	}
+	valueObj := v.Get("value")
+	if valueObj.IsNull() {
+		return "", nil, false, nil
+	}
+	return valueObj.String(), toMetadata(v.Get("metadata")), true, nil
+}
+
+// GetReaderWithMetadata gets stream value and its metadata by the specified key.
+//   - metadata is nil if the value has no metadata. It can be decoded into a Go value with Decode.
+//   - if the key is not found, returns nil reader and nil metadata.
+//   - if a network error happens, returns error.
+func (ns *Namespace) GetReaderWithMetadata(key string, opts *GetOptions) (io.Reader, *codec.Value, error) {
+	p := ns.instance.Call("getWithMetadata", key, opts.toJS("stream"))
+	v, err := jsutil.AwaitPromise(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	value := v.Get("value")
+	if value.IsNull() {
+		return nil, nil, nil
+	}
+	return jsutil.ConvertReadableStreamToReadCloser(value), toMetadata(v.Get("metadata")), nil
+}
+
+// LookupString gets string value by the specified key.
+//   - ok is false if the key is not found, so a missing key can be told apart from an empty string.
+//   - if a network error happens, returns error.
+func (ns *Namespace) LookupString(key string, opts *GetOptions) (value string, ok bool, err error) {
+	p := ns.instance.Call("get", key, opts.toJS("text"))
+	v, err := jsutil.AwaitPromise(p)
+	if err != nil {
+		return "", false, err
+	}
+	if v.IsNull() {
+		return "", false, nil
+	}
+	return v.String(), true, nil
+}
+
+// GetBytes gets byte array value by the specified key.
+//   - ok is false if the key is not found.
+//   - if a network error happens, returns error.
+func (ns *Namespace) GetBytes(key string, opts *GetOptions) (value []byte, ok bool, err error) {
+	p := ns.instance.Call("get", key, opts.toJS("arrayBuffer"))
+	v, err := jsutil.AwaitPromise(p)
+	if err != nil {
+		return nil, false, err
+	}
+	if v.IsNull() {
+		return nil, false, nil
+	}
+	ua := jsutil.Uint8ArrayClass.New(v)
+	b := make([]byte, ua.Get("byteLength").Int())
+	js.CopyBytesToGo(b, ua)
+	return b, true, nil
+}
+
+// GetJSON gets JSON value by the specified key, and decodes it into the value pointed to by ptr with encoding/json.
+//   - ok is false if the key is not found. In this case, ptr is left as is.
+//   - if a network error happens, returns error.
+func (ns *Namespace) GetJSON(key string, ptr any, opts *GetOptions) (ok bool, err error) {
+	value, ok, err := ns.LookupString(key, opts)
+	if err != nil || !ok {
+		return false, err
+	}
+	if err := json.Unmarshal([]byte(value), ptr); err != nil {
+		return false, fmt.Errorf("failed to decode value of %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// getMultiple gets values of the specified keys in one round trip.
+//   - keys not found are not included in the result.
+func (ns *Namespace) getMultiple(keys []string, type_ string, opts *GetOptions) (map[string]js.Value, error) {
+	keysObj := jsutil.NewArray(len(keys))
+	for i, key := range keys {
+		keysObj.SetIndex(i, key)
+	}
+	p := ns.instance.Call("get", keysObj, opts.toJS(type_))
+	v, err := jsutil.AwaitPromise(p)
+	if err != nil {
+		return nil, err
+	}
+	entries := jsutil.ArrayFrom(v.Call("entries"))
+	result := make(map[string]js.Value, entries.Length())
+	for i := 0; i < entries.Length(); i++ {
+		entry := entries.Index(i)
+		if value := entry.Index(1); !value.IsNull() {
+			result[entry.Index(0).String()] = value
+		}
+	}
+	return result, nil
+}
+
+// GetMultipleStrings gets string values of the specified keys in one round trip.
+//   - keys not found are not included in the result.
+//   - up to 100 keys can be given at once.
+//   - if a network error happens, returns error.
+func (ns *Namespace) GetMultipleStrings(keys []string, opts *GetOptions) (map[string]string, error) {
+	values, err := ns.getMultiple(keys, "text", opts)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]string, len(values))
+	for key, value := range values {
+		result[key] = value.String()
+	}
+	return result, nil
+}
+
+// GetMultipleJSON gets JSON values of the specified keys in one round trip.
+//   - each value can be decoded into a Go value with Decode.
+//   - keys not found are not included in the result.
+//   - up to 100 keys can be given at once.
+//   - if a network error happens, returns error.
+func (ns *Namespace) GetMultipleJSON(keys []string, opts *GetOptions) (map[string]*codec.Value, error) {
+	values, err := ns.getMultiple(keys, "json", opts)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]*codec.Value, len(values))
+	for key, value := range values {
+		result[key] = codec.NewValue(value, codec.JSON)
+	}
+	return result, nil
+}
@@ -0,0 +1,36 @@
+package kv
+
+import (
+	"context"
+	"iter"
+)
+
+// All returns an iterator over the keys which start with prefix.
+//   - pages of List are fetched transparently while the iteration continues.
+//   - an empty prefix iterates over all keys of the namespace.
+//   - if an error happens, the error is yielded with a nil key and the iteration stops.
+func (ns *Namespace) All(ctx context.Context, prefix string) iter.Seq2[*ListKey, error] {
+	return func(yield func(*ListKey, error) bool) {
+		opts := &ListOptions{Prefix: prefix}
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+			result, err := ns.List(opts)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, key := range result.Keys {
+				if !yield(key, nil) {
+					return
+				}
+			}
+			if result.ListComplete || result.Cursor == "" {
+				return
+			}
+			opts.Cursor = result.Cursor
+		}
+	}
+}
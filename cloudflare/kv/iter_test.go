@@ -0,0 +1,54 @@
+package kv
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNamespace_All(t *testing.T) {
+	ns := newFakeNamespace(t)
+	for _, key := range []string{"user:1", "user:2", "user:3", "user:4", "user:5", "post:1"} {
+		if err := ns.PutString(key, "v", nil); err != nil {
+			t.Fatalf("PutString failed: %v", err)
+		}
+	}
+
+	var got []string
+	for key, err := range ns.All(context.Background(), "user:") {
+		if err != nil {
+			t.Fatalf("All failed: %v", err)
+		}
+		got = append(got, key.Name)
+	}
+	want := []string{"user:1", "user:2", "user:3", "user:4", "user:5"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("All() = %v, want %v", got, want)
+	}
+
+	got = nil
+	for key, err := range ns.All(context.Background(), "") {
+		if err != nil {
+			t.Fatalf("All failed: %v", err)
+		}
+		got = append(got, key.Name)
+		if len(got) == 3 {
+			break
+		}
+	}
+	if len(got) != 3 {
+		t.Errorf("len(All()) after break = %d, want 3", len(got))
+	}
+}
+
+func TestNamespace_All_ContextCanceled(t *testing.T) {
+	ns := newFakeNamespace(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for key, err := range ns.All(ctx, "") {
+		if !errors.Is(err, context.Canceled) || key != nil {
+			t.Fatalf("All() yielded %v, %v, want context.Canceled", key, err)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package kv
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall/js"
+	"testing"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// fakePageSize is the number of keys listed in a page by the fake namespace.
+const fakePageSize = 2
+
+type fakeEntry struct {
+	value    string
+	metadata js.Value
+}
+
+// newFakeNamespace returns a Namespace backed by an in-memory JS KV namespace.
+func newFakeNamespace(t *testing.T) *Namespace {
+	t.Helper()
+	entries := map[string]*fakeEntry{}
+	resolve := func(v any) js.Value {
+		return jsutil.PromiseClass.Call("resolve", v)
+	}
+	toValue := func(e *fakeEntry, opts js.Value) js.Value {
+		if e == nil {
+			return js.Null()
+		}
+		typ := "text"
+		if opts.Type() == js.TypeObject && opts.Get("type").Truthy() {
+			typ = opts.Get("type").String()
+		}
+		switch typ {
+		case "stream":
+			return jsutil.ResponseClass.New(e.value).Get("body")
+		case "arrayBuffer":
+			ua := jsutil.NewUint8Array(len(e.value))
+			js.CopyBytesToJS(ua, []byte(e.value))
+			return ua.Get("buffer")
+		case "json":
+			return jsutil.JSONObject.Call("parse", e.value)
+		}
+		return js.ValueOf(e.value)
+	}
+	optionAt := func(args []js.Value, i int) js.Value {
+		if len(args) <= i {
+			return js.Undefined()
+		}
+		return args[i]
+	}
+	funcs := map[string]js.Func{
+		"put": js.FuncOf(func(_ js.Value, args []js.Value) any {
+			key := args[0].String()
+			metadata := js.Null()
+			if opts := optionAt(args, 2); opts.Type() == js.TypeObject && !opts.Get("metadata").IsUndefined() {
+				metadata = opts.Get("metadata")
+			}
+			if args[1].Type() == js.TypeString {
+				entries[key] = &fakeEntry{value: args[1].String(), metadata: metadata}
+				return resolve(js.Undefined())
+			}
+			// ArrayBuffer and ReadableStream values are read through Response.
+			var store js.Func
+			store = js.FuncOf(func(_ js.Value, pArgs []js.Value) any {
+				defer store.Release()
+				entries[key] = &fakeEntry{value: pArgs[0].String(), metadata: metadata}
+				return js.Undefined()
+			})
+			return jsutil.ResponseClass.New(args[1]).Call("text").Call("then", store)
+		}),
+		"get": js.FuncOf(func(_ js.Value, args []js.Value) any {
+			if args[0].Type() == js.TypeString {
+				return resolve(toValue(entries[args[0].String()], optionAt(args, 1)))
+			}
+			result := js.Global().Get("Map").New()
+			for i := 0; i < args[0].Length(); i++ {
+				key := args[0].Index(i).String()
+				result.Call("set", key, toValue(entries[key], optionAt(args, 1)))
+			}
+			return resolve(result)
+		}),
+		"getWithMetadata": js.FuncOf(func(_ js.Value, args []js.Value) any {
+			e := entries[args[0].String()]
+			result := jsutil.NewObject()
+			result.Set("value", toValue(e, optionAt(args, 1)))
+			result.Set("metadata", js.Null())
+			if e != nil {
+				result.Set("metadata", e.metadata)
+			}
+			return resolve(result)
+		}),
+		"list": js.FuncOf(func(_ js.Value, args []js.Value) any {
+			// pages are split by fakePageSize keys, and the cursor is the index of the next key.
+			opts := optionAt(args, 0)
+			var prefix string
+			var start int
+			if opts.Type() == js.TypeObject {
+				prefix = jsutil.MaybeString(opts.Get("prefix"))
+				if cursor := opts.Get("cursor"); !cursor.IsUndefined() {
+					start, _ = strconv.Atoi(cursor.String())
+				}
+			}
+			names := make([]string, 0, len(entries))
+			for name := range entries {
+				if strings.HasPrefix(name, prefix) {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+			end := min(start+fakePageSize, len(names))
+			keys := jsutil.NewArray(end - start)
+			for i, name := range names[start:end] {
+				key := jsutil.NewObject()
+				key.Set("name", name)
+				if m := entries[name].metadata; !m.IsNull() {
+					key.Set("metadata", m)
+				}
+				keys.SetIndex(i, key)
+			}
+			result := jsutil.NewObject()
+			result.Set("keys", keys)
+			result.Set("list_complete", end == len(names))
+			if end < len(names) {
+				result.Set("cursor", strconv.Itoa(end))
+			}
+			return resolve(result)
+		}),
+	}
+	obj := jsutil.NewObject()
+	for name, fn := range funcs {
+		obj.Set(name, fn)
+	}
+	t.Cleanup(func() {
+		for _, fn := range funcs {
+			fn.Release()
+		}
+	})
+	return &Namespace{instance: obj}
+}
+
+type fileMetadata struct {
+	Hash  string `json:"hash"`
+	Owner string `json:"owner"`
+}
+
+func TestNamespace_Metadata(t *testing.T) {
+	ns := newFakeNamespace(t)
+	want := fileMetadata{Hash: "abc", Owner: "alice"}
+	if err := ns.PutString("with-metadata", "hello", &PutOptions{Metadata: want}); err != nil {
+		t.Fatalf("PutString failed: %v", err)
+	}
+	if err := ns.PutString("without-metadata", "world", nil); err != nil {
+		t.Fatalf("PutString failed: %v", err)
+	}
+
+	value, metadata, ok, err := ns.GetStringWithMetadata("with-metadata", nil)
+	if err != nil {
+		t.Fatalf("GetStringWithMetadata failed: %v", err)
+	}
+	if !ok || value != "hello" {
+		t.Errorf("value = %q, want %q", value, "hello")
+	}
+	var got fileMetadata
+	if err := metadata.Decode(&got); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("metadata = %+v, want %+v", got, want)
+	}
+
+	r, metadata, err := ns.GetReaderWithMetadata("without-metadata", nil)
+	if err != nil {
+		t.Fatalf("GetReaderWithMetadata failed: %v", err)
+	}
+	if b, _ := io.ReadAll(r); string(b) != "world" {
+		t.Errorf("value = %q, want %q", b, "world")
+	}
+	if metadata != nil {
+		t.Errorf("metadata = %v, want nil", metadata)
+	}
+
+	value, metadata, ok, err = ns.GetStringWithMetadata("missing", nil)
+	if err != nil || ok || value != "" || metadata != nil {
+		t.Errorf("GetStringWithMetadata(missing) = %q, %v, %v, %v, want not found", value, metadata, ok, err)
+	}
+
+	result, err := ns.List(nil)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(result.Keys) != 2 {
+		t.Fatalf("len(Keys) = %d, want 2", len(result.Keys))
+	}
+	got = fileMetadata{}
+	if err := result.Keys[0].Metadata.Decode(&got); err != nil || got != want {
+		t.Errorf("Keys[0].Metadata = %+v, %v, want %+v", got, err, want)
+	}
+	if result.Keys[1].Metadata != nil {
+		t.Errorf("Keys[1].Metadata = %v, want nil", result.Keys[1].Metadata)
+	}
+
+	if err := ns.PutString("empty", "", nil); err != nil {
+		t.Fatalf("PutString failed: %v", err)
+	}
+	value, _, ok, err = ns.GetStringWithMetadata("empty", nil)
+	if err != nil || !ok || value != "" {
+		t.Errorf("GetStringWithMetadata(empty) = %q, %v, %v, want empty string found", value, ok, err)
+	}
+}
+
+func TestNamespace_TypedGet(t *testing.T) {
+	ns := newFakeNamespace(t)
+	for key, value := range map[string]string{
+		"empty": "",
+		"bytes": "\x00\x01",
+		"json":  `{"hash":"abc","owner":"alice"}`,
+	} {
+		if err := ns.PutString(key, value, nil); err != nil {
+			t.Fatalf("PutString failed: %v", err)
+		}
+	}
+
+	if value, ok, err := ns.LookupString("empty", nil); err != nil || !ok || value != "" {
+		t.Errorf("LookupString(empty) = %q, %v, %v, want empty string found", value, ok, err)
+	}
+	if value, ok, err := ns.LookupString("missing", nil); err != nil || ok || value != "" {
+		t.Errorf("LookupString(missing) = %q, %v, %v, want not found", value, ok, err)
+	}
+
+	b, ok, err := ns.GetBytes("bytes", nil)
+	if err != nil || !ok || string(b) != "\x00\x01" {
+		t.Errorf("GetBytes(bytes) = %v, %v, %v, want [0 1]", b, ok, err)
+	}
+	if _, ok, err := ns.GetBytes("missing", nil); err != nil || ok {
+		t.Errorf("GetBytes(missing) = %v, %v, want not found", ok, err)
+	}
+
+	var got fileMetadata
+	if ok, err := ns.GetJSON("json", &got, nil); err != nil || !ok {
+		t.Fatalf("GetJSON(json) = %v, %v, want found", ok, err)
+	}
+	if want := (fileMetadata{Hash: "abc", Owner: "alice"}); got != want {
+		t.Errorf("GetJSON(json) decoded %+v, want %+v", got, want)
+	}
+	if ok, err := ns.GetJSON("missing", &got, nil); err != nil || ok {
+		t.Errorf("GetJSON(missing) = %v, %v, want not found", ok, err)
+	}
+}
+
+func TestNamespace_GetMultiple(t *testing.T) {
+	ns := newFakeNamespace(t)
+	for key, value := range map[string]string{
+		"flag-a": "true",
+		"flag-b": `{"hash":"abc"}`,
+	} {
+		if err := ns.PutString(key, value, nil); err != nil {
+			t.Fatalf("PutString failed: %v", err)
+		}
+	}
+	keys := []string{"flag-a", "flag-b", "missing"}
+
+	strs, err := ns.GetMultipleStrings(keys, nil)
+	if err != nil {
+		t.Fatalf("GetMultipleStrings failed: %v", err)
+	}
+	if len(strs) != 2 || strs["flag-a"] != "true" || strs["flag-b"] != `{"hash":"abc"}` {
+		t.Errorf("GetMultipleStrings() = %v, want flag-a and flag-b", strs)
+	}
+	if _, ok := strs["missing"]; ok {
+		t.Error("GetMultipleStrings() contains missing key")
+	}
+
+	values, err := ns.GetMultipleJSON(keys, nil)
+	if err != nil {
+		t.Fatalf("GetMultipleJSON failed: %v", err)
+	}
+	if len(values) != 2 {
+		t.Fatalf("len(GetMultipleJSON()) = %d, want 2", len(values))
+	}
+	var flag bool
+	if err := values["flag-a"].Decode(&flag); err != nil || !flag {
+		t.Errorf("flag-a = %v, %v, want true", flag, err)
+	}
+	var m fileMetadata
+	if err := values["flag-b"].Decode(&m); err != nil || m.Hash != "abc" {
+		t.Errorf("flag-b = %+v, %v, want hash abc", m, err)
+	}
+}
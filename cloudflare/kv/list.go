@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"syscall/js"
 
+	"github.com/syumai/workers/cloudflare/codec"
 	"github.com/syumai/workers/internal/jsutil"
 )
 
@@ -38,7 +39,9 @@ type ListKey struct {
 	Name string
 	// Expiration is an expiration of KV value cache. The value `0` means no expiration.
 	Expiration int
-	// Metadata   map[string]any // TODO: implement
+	// Metadata is the metadata stored with the value. Metadata is nil if the value has no metadata.
+	// Metadata can be decoded into a Go value with Metadata.Decode.
+	Metadata *codec.Value
 }
 
 // toListKey converts JavaScript side's KVNamespaceListKey to *ListKey.
@@ -52,7 +55,7 @@ func toListKey(v js.Value) (*ListKey, error) {
 	return &ListKey{
 		Name:       v.Get("name").String(),
 		Expiration: exp,
-		// Metadata // TODO: implement. This may return an error, so this func signature has an error in return parameters.
+		Metadata:   toMetadata(v.Get("metadata")),
 	}, nil
 }
 
@@ -1,6 +1,7 @@
 package kv
 
 import (
+	"context"
 	"fmt"
 	"syscall/js"
 
@@ -18,8 +19,17 @@ type Namespace struct {
 //   - variable name must be defined in wrangler.toml as kv_namespace's binding.
 //   - if the given variable name doesn't exist on runtime context, returns error.
 //   - This function panics when a runtime context is not found.
+//   - On an instance shared across events (workers-assets-gen -instance=shared), this function fails since the event is unknown. Use NewNamespaceFromContext instead.
 func NewNamespace(varName string) (*Namespace, error) {
-	inst := cfruntimecontext.MustGetRuntimeContextEnv().Get(varName)
+	return NewNamespaceFromContext(context.Background(), varName)
+}
+
+// NewNamespaceFromContext returns Namespace for given variable name resolved from the runtime context attached to ctx.
+//   - if the runtime context is not attached to ctx, this function behaves the same as NewNamespace.
+//   - if the given variable name doesn't exist on runtime context, returns error.
+//   - This function panics when a runtime context is not found.
+func NewNamespaceFromContext(ctx context.Context, varName string) (*Namespace, error) {
+	inst := cfruntimecontext.MustGetRuntimeContextEnvFromContext(ctx).Get(varName)
 	if inst.IsUndefined() {
 		return nil, fmt.Errorf("%s is undefined", varName)
 	}
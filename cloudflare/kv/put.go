@@ -1,9 +1,11 @@
 package kv
 
 import (
+	"fmt"
 	"io"
 	"syscall/js"
 
+	"github.com/syumai/workers/cloudflare/codec"
 	"github.com/syumai/workers/internal/jsutil"
 )
 
@@ -12,12 +14,14 @@ import (
 type PutOptions struct {
 	Expiration    int
 	ExpirationTTL int
-	// Metadata // TODO: implement
+	// Metadata is stored with the value. Metadata must be serializable with encoding/json.
+	//   - the serialized metadata must be up to 1024 bytes.
+	Metadata any
 }
 
-func (opts *PutOptions) toJS() js.Value {
+func (opts *PutOptions) toJS() (js.Value, error) {
 	if opts == nil {
-		return js.Undefined()
+		return js.Undefined(), nil
 	}
 	obj := jsutil.NewObject()
 	if opts.Expiration != 0 {
@@ -26,14 +30,25 @@ func (opts *PutOptions) toJS() js.Value {
 	if opts.ExpirationTTL != 0 {
 		obj.Set("expirationTtl", opts.ExpirationTTL)
 	}
-	return obj
+	if opts.Metadata != nil {
+		metadata, err := codec.JSON.Encode(opts.Metadata)
+		if err != nil {
+			return js.Value{}, fmt.Errorf("failed to encode metadata: %w", err)
+		}
+		obj.Set("metadata", metadata)
+	}
+	return obj, nil
 }
 
 // PutString puts string value into KV with key.
 //   - if a network error happens, returns error.
 func (ns *Namespace) PutString(key string, value string, opts *PutOptions) error {
-	p := ns.instance.Call("put", key, value, opts.toJS())
-	_, err := jsutil.AwaitPromise(p)
+	optsObj, err := opts.toJS()
+	if err != nil {
+		return err
+	}
+	p := ns.instance.Call("put", key, value, optsObj)
+	_, err = jsutil.AwaitPromise(p)
 	if err != nil {
 		return err
 	}
@@ -41,17 +56,39 @@ func (ns *Namespace) PutString(key string, value string, opts *PutOptions) error
 }
 
 // PutReader puts stream value into KV with key.
-//   - This method copies all bytes into memory for implementation restriction.
+//   - if value is an unread incoming request body, its stream is forwarded to KV without being copied into memory.
+//   - otherwise, this method copies all bytes into memory for implementation restriction. Use PutStream if the size is known.
 //   - if a network error happens, returns error.
 func (ns *Namespace) PutReader(key string, value io.Reader, opts *PutOptions) error {
-	// fetch body cannot be ReadableStream. see: https://github.com/whatwg/fetch/issues/1438
-	b, err := io.ReadAll(value)
+	return ns.PutStream(key, value, -1, opts)
+}
+
+// PutStream puts stream value of the given size in bytes into KV with key.
+//   - if value is an unread incoming request body, its stream is forwarded to KV without being copied into memory.
+//   - otherwise, value is streamed to KV through FixedLengthStream without buffering the whole value in memory.
+//   - if size is negative or FixedLengthStream is not available, values other than an unread request body are copied into memory.
+//   - if the length of value does not match size, returns error.
+//   - if a network error happens, returns error.
+func (ns *Namespace) PutStream(key string, value io.Reader, size int64, opts *PutOptions) error {
+	optsObj, err := opts.toJS()
 	if err != nil {
 		return err
 	}
-	ua := jsutil.NewUint8Array(len(b))
-	js.CopyBytesToJS(ua, b)
-	p := ns.instance.Call("put", key, ua.Get("buffer"), opts.toJS())
+	body, ok := jsutil.ConvertReaderToStreamBody(value, size)
+	if !ok {
+		// fetch body cannot be ReadableStream. see: https://github.com/whatwg/fetch/issues/1438
+		b, err := io.ReadAll(value)
+		if err != nil {
+			return err
+		}
+		if size >= 0 && int64(len(b)) != size {
+			return fmt.Errorf("size of value is %d bytes, want %d bytes", len(b), size)
+		}
+		ua := jsutil.NewUint8Array(len(b))
+		js.CopyBytesToJS(ua, b)
+		body = ua.Get("buffer")
+	}
+	p := ns.instance.Call("put", key, body, optsObj)
 	_, err = jsutil.AwaitPromise(p)
 	if err != nil {
 		return err
@@ -0,0 +1,68 @@
+package kv
+
+import (
+	"io"
+	"strings"
+	"syscall/js"
+	"testing"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// recordPutValues wraps put of the namespace to record the values given to it.
+func recordPutValues(t *testing.T, ns *Namespace) map[string]js.Value {
+	t.Helper()
+	values := map[string]js.Value{}
+	put := ns.instance.Get("put")
+	record := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		values[args[0].String()] = args[1]
+		anyArgs := make([]any, len(args))
+		for i, arg := range args {
+			anyArgs[i] = arg
+		}
+		return put.Call("call", append([]any{ns.instance}, anyArgs...)...)
+	})
+	t.Cleanup(record.Release)
+	ns.instance.Set("put", record)
+	return values
+}
+
+func TestNamespace_PutStream(t *testing.T) {
+	ns := newFakeNamespace(t)
+	values := recordPutValues(t, ns)
+	rawBody := jsutil.ResponseClass.New("from request").Get("body")
+	requestBody := jsutil.ConvertReadableStreamToReadCloser(rawBody)
+	readBody := jsutil.ConvertReadableStreamToReadCloser(jsutil.ResponseClass.New("from read request").Get("body"))
+	if _, err := io.ReadFull(readBody, make([]byte, len("from "))); err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	if err := ns.PutStream("mismatch", strings.NewReader("mismatch"), 3, nil); err == nil {
+		t.Error("PutStream() with mismatched size succeeded, want error")
+	}
+	for key, put := range map[string]func() error{
+		"request": func() error { return ns.PutReader("request", requestBody, nil) },
+		"read":    func() error { return ns.PutReader("read", readBody, nil) },
+		"sized":   func() error { return ns.PutStream("sized", strings.NewReader("sized"), 5, nil) },
+		"reader":  func() error { return ns.PutReader("reader", strings.NewReader("reader"), nil) },
+	} {
+		if err := put(); err != nil {
+			t.Fatalf("put %s failed: %v", key, err)
+		}
+	}
+	if !values["request"].Equal(rawBody) {
+		t.Error("the raw stream of the unread request body was not forwarded to put")
+	}
+	if values["read"].InstanceOf(jsutil.ReadableStreamClass) {
+		t.Error("the partly read request body must not be forwarded as a stream")
+	}
+	for key, want := range map[string]string{
+		"request": "from request",
+		"read":    "read request",
+		"sized":   "sized",
+		"reader":  "reader",
+	} {
+		if got, ok, err := ns.LookupString(key, nil); err != nil || !ok || got != want {
+			t.Errorf("LookupString(%s) = %q, %v, %v, want %q", key, got, ok, err, want)
+		}
+	}
+}
@@ -9,10 +9,20 @@ import (
 // MessageSendRequest is a wrapper type used for sending message batches.
 // see: https://developers.cloudflare.com/queues/configuration/javascript-apis/#messagesendrequest
 type MessageSendRequest struct {
-	body    js.Value
+	body js.Value
+	// value is the Go value encoded by the codec of the Producer on sending. It is used if body is not set.
+	value   any
 	options *sendOptions
 }
 
+// NewMessageSendRequest creates a single message of any Go value to be batched before sending to a queue.
+// v is encoded by the codec of the Producer on Producer.SendBatch.
+func NewMessageSendRequest(v any, opts ...SendOption) *MessageSendRequest {
+	m := newMessageSendRequest(js.Value{}, "", opts...)
+	m.value = v
+	return m
+}
+
 // NewTextMessageSendRequest creates a single text message to be batched before sending to a queue.
 func NewTextMessageSendRequest(content string, opts ...SendOption) *MessageSendRequest {
 	return newMessageSendRequest(js.ValueOf(content), contentTypeText, opts...)
@@ -44,9 +54,22 @@ func newMessageSendRequest(body js.Value, contentType contentType, opts ...SendO
 	return &MessageSendRequest{body: body, options: &options}
 }
 
-func (m *MessageSendRequest) toJS() js.Value {
+func (m *MessageSendRequest) toJS(p *Producer) (js.Value, error) {
+	body := m.body
+	options := *m.options
+	if m.options.ContentType == "" {
+		var (
+			contentType contentType
+			err         error
+		)
+		body, contentType, err = p.encode(m.value)
+		if err != nil {
+			return js.Value{}, err
+		}
+		options.ContentType = contentType
+	}
 	obj := jsutil.NewObject()
-	obj.Set("body", m.body)
-	obj.Set("options", m.options.toJS())
-	return obj
+	obj.Set("body", body)
+	obj.Set("options", options.toJS())
+	return obj, nil
 }
@@ -0,0 +1,65 @@
+package queues
+
+import (
+	"encoding/json"
+	"reflect"
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare/codec"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// decodeBody decodes the message body into the value pointed to by ptr as described in Message.Decode.
+func decodeBody(body js.Value, ptr any) error {
+	if p, ok := ptr.(*js.Value); ok {
+		*p = body
+		return nil
+	}
+	if rv := reflect.ValueOf(ptr); rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		elem := rv.Elem()
+		switch {
+		case elem.Kind() == reflect.String:
+			if body.Type() == js.TypeString {
+				elem.SetString(body.String())
+				return nil
+			}
+			if b, ok := bytesOf(body); ok {
+				elem.SetString(string(b))
+				return nil
+			}
+		case elem.Kind() == reflect.Slice && elem.Type().Elem().Kind() == reflect.Uint8:
+			if body.Type() == js.TypeString {
+				elem.SetBytes([]byte(body.String()))
+				return nil
+			}
+			if b, ok := bytesOf(body); ok {
+				elem.SetBytes(b)
+				return nil
+			}
+		}
+	}
+	if body.Type() == js.TypeString {
+		return json.Unmarshal([]byte(body.String()), ptr)
+	}
+	if b, ok := bytesOf(body); ok {
+		return json.Unmarshal(b, ptr)
+	}
+	return codec.JSON.Decode(body, ptr)
+}
+
+// bytesOf copies the byte array body into a new slice.
+// ok is false if body is neither an Uint8Array, Uint8ClampedArray nor ArrayBuffer.
+func bytesOf(body js.Value) (b []byte, ok bool) {
+	if body.Type() != js.TypeObject {
+		return nil, false
+	}
+	if body.InstanceOf(jsutil.ArrayBufferClass) {
+		body = jsutil.Uint8ArrayClass.New(body)
+	}
+	if !body.InstanceOf(jsutil.Uint8ArrayClass) && !body.InstanceOf(jsutil.Uint8ClampedArrayClass) {
+		return nil, false
+	}
+	b = make([]byte, body.Get("byteLength").Int())
+	js.CopyBytesToGo(b, body)
+	return b, true
+}
@@ -1,10 +1,14 @@
 package queues
 
 import (
+	"context"
 	"fmt"
+	"runtime/debug"
 	"syscall/js"
 
 	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/recovery"
+	"github.com/syumai/workers/internal/runtimecontext"
 )
 
 // Consumer is a function that received a batch of messages from Cloudflare Queues.
@@ -14,22 +18,36 @@ import (
 // acknowledgment until the task is completed witout blocking the queue consumption.
 type Consumer func(batch *MessageBatch) error
 
-var consumer Consumer
+// batchHandler handles a batch of messages received with the context of the event.
+// It is set by Consume, ConsumeNonBlock, ConsumeMessages and ConsumeMessagesNonBlock.
+type batchHandler func(ctx context.Context, batch *MessageBatch) error
+
+var consumer batchHandler
+
+func setConsumer(f Consumer) {
+	consumer = func(_ context.Context, batch *MessageBatch) error {
+		return f(batch)
+	}
+}
 
 func init() {
 	handleBatchCallback := js.FuncOf(func(this js.Value, args []js.Value) any {
 		batch := args[0]
+		runtimeObj := jsutil.RuntimeContext
+		if len(args) > 1 {
+			runtimeObj = args[1]
+		}
 		var cb js.Func
 		cb = js.FuncOf(func(_ js.Value, pArgs []js.Value) any {
 			defer cb.Release()
 			resolve := pArgs[0]
 			reject := pArgs[1]
 			go func() {
-				if len(args) > 1 {
+				if len(args) > 2 {
 					reject.Invoke(jsutil.Errorf("too many args given to handleQueueMessageBatch: %d", len(args)))
 					return
 				}
-				err := consumeBatch(batch)
+				err := consumeBatch(batch, runtimeObj)
 				if err != nil {
 					reject.Invoke(jsutil.Error(err.Error()))
 					return
@@ -43,13 +61,22 @@ func init() {
 	jsutil.Binding.Set("handleQueueMessageBatch", handleBatchCallback)
 }
 
-func consumeBatch(batch js.Value) error {
+// consumeBatch runs the Consumer with the given batch.
+// A panic in the Consumer is recovered, reported and returned as an error.
+func consumeBatch(batch, runtimeObj js.Value) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			recovery.Report(nil, v, debug.Stack())
+			err = recovery.Error(v)
+		}
+	}()
 	b, err := newMessageBatch(batch)
 	if err != nil {
 		return fmt.Errorf("failed to parse message batch: %v", err)
 	}
 
-	if err := consumer(b); err != nil {
+	ctx := runtimecontext.New(context.Background(), batch, runtimeObj)
+	if err := consumer(ctx, b); err != nil {
 		return err
 	}
 	return nil
@@ -63,7 +90,7 @@ func ready()
 // only worker's purpose is to be the consumer of a Cloudflare Queue.
 // In case the worker has other purposes (e.g. handling HTTP requests), use ConsumeNonBlock instead.
 func Consume(f Consumer) {
-	consumer = f
+	setConsumer(f)
 	ready()
 	select {}
 }
@@ -73,5 +100,5 @@ func Consume(f Consumer) {
 // The worker will not block receiving messages and will continue to execute other tasks.
 // ConsumeNonBlock should be called before setting other blocking handlers (e.g. workers.Serve).
 func ConsumeNonBlock(f Consumer) {
-	consumer = f
+	setConsumer(f)
 }
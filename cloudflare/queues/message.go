@@ -5,6 +5,7 @@ import (
 	"syscall/js"
 	"time"
 
+	"github.com/syumai/workers/cloudflare/codec"
 	"github.com/syumai/workers/internal/jsutil"
 )
 
@@ -75,3 +76,19 @@ func (m *Message) BytesBody() ([]byte, error) {
 	js.CopyBytesToGo(b, m.Body)
 	return b, nil
 }
+
+// Decode decodes the message body into the value pointed to by ptr.
+//   - pointers to string types (including named ones) accept text and byte array bodies.
+//   - pointers to byte slice types (including named ones) accept byte array and text bodies.
+//   - *js.Value receives the body as is.
+//   - Other types are decoded with encoding/json. Text and byte array bodies are treated as JSON texts.
+//
+// Decode is symmetric with Producer.Send with the default codec. For the other codecs, use DecodeWithCodec.
+func (m *Message) Decode(ptr any) error {
+	return decodeBody(m.Body, ptr)
+}
+
+// DecodeWithCodec decodes the message body into the value pointed to by ptr by c.
+func (m *Message) DecodeWithCodec(c codec.Codec, ptr any) error {
+	return c.Decode(m.Body, ptr)
+}
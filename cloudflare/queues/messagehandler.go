@@ -0,0 +1,128 @@
+package queues
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/codec"
+	"github.com/syumai/workers/internal/recovery"
+)
+
+// MessageHandler is a function that handles a single message received from Cloudflare Queues.
+// body is the message body decoded into T in the same way as Message.Decode, or by ConsumeOptions.Codec if it is set.
+// The message is acknowledged if the function returns nil, and retried otherwise.
+type MessageHandler[T any] func(ctx context.Context, body T, msg *Message) error
+
+// ConsumeOptions configures how messages are handled by ConsumeMessages.
+type ConsumeOptions struct {
+	// Backoff returns the delay before the failed message is retried.
+	// attempts is the number of times the message has been delivered, starting from 1.
+	// If Backoff is nil, the retry delay configured for the queue is used.
+	Backoff func(attempts int) time.Duration
+	// Concurrency is the maximum number of messages handled concurrently within a batch.
+	// If Concurrency is less than 1, messages are handled one by one.
+	Concurrency int
+	// Codec decodes message bodies. If Codec is nil, bodies are decoded in the same way as Message.Decode.
+	Codec codec.Codec
+	// OnError is called when a message failed to be decoded or handled, before the message is retried.
+	// If OnError is nil, the error is logged with log/slog.
+	OnError func(msg *Message, err error)
+}
+
+// ExponentialBackoff returns a Backoff function of ConsumeOptions which doubles the delay from base on every attempt up to max.
+func ExponentialBackoff(base, max time.Duration) func(attempts int) time.Duration {
+	return func(attempts int) time.Duration {
+		d := base
+		for i := 1; i < attempts && d < max; i++ {
+			d *= 2
+		}
+		return min(d, max)
+	}
+}
+
+// ConsumeMessages sets the MessageHandler to receive messages from Cloudflare Queues one by one.
+// Each message is decoded into T, then acknowledged on success or retried with the backoff of opts on failure.
+// NOTE: This function will block the current goroutine and is intended to be used as long as the
+// only worker's purpose is to be the consumer of a Cloudflare Queue.
+// In case the worker has other purposes (e.g. handling HTTP requests), use ConsumeMessagesNonBlock instead.
+func ConsumeMessages[T any](h MessageHandler[T], opts *ConsumeOptions) {
+	ConsumeMessagesNonBlock(h, opts)
+	ready()
+	select {}
+}
+
+// ConsumeMessagesNonBlock sets the MessageHandler to receive messages from Cloudflare Queues one by one.
+// ConsumeMessagesNonBlock should be called before setting other blocking handlers (e.g. workers.Serve).
+func ConsumeMessagesNonBlock[T any](h MessageHandler[T], opts *ConsumeOptions) {
+	if opts == nil {
+		opts = &ConsumeOptions{}
+	}
+	consumer = func(ctx context.Context, batch *MessageBatch) error {
+		handleMessages(ctx, batch, h, opts)
+		return nil
+	}
+}
+
+// handleMessages handles messages of the batch with at most opts.Concurrency goroutines.
+func handleMessages[T any](ctx context.Context, batch *MessageBatch, h MessageHandler[T], opts *ConsumeOptions) {
+	concurrency := max(opts.Concurrency, 1)
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, msg := range batch.Messages {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(msg *Message) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := handleMessage(ctx, msg, h, opts.Codec); err != nil {
+				opts.onError(msg, err)
+				var retryOpts []RetryOption
+				if opts.Backoff != nil {
+					retryOpts = append(retryOpts, WithRetryDelay(opts.Backoff(msg.Attempts)))
+				}
+				msg.Retry(retryOpts...)
+				return
+			}
+			msg.Ack()
+		}(msg)
+	}
+	wg.Wait()
+}
+
+// handleMessage decodes the message body and runs the MessageHandler.
+// A panic in the MessageHandler is recovered, reported and returned as an error.
+func handleMessage[T any](ctx context.Context, msg *Message, h MessageHandler[T], c codec.Codec) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			recovery.Report(nil, v, debug.Stack())
+			err = recovery.Error(v)
+		}
+	}()
+	var body T
+	decode := msg.Decode
+	if c != nil {
+		decode = func(ptr any) error { return msg.DecodeWithCodec(c, ptr) }
+	}
+	if err := decode(&body); err != nil {
+		return fmt.Errorf("failed to decode message body: %w", err)
+	}
+	return h(ctx, body, msg)
+}
+
+func (o *ConsumeOptions) onError(msg *Message, err error) {
+	if o.OnError != nil {
+		o.OnError(msg, err)
+		return
+	}
+	slog.Error("queues: failed to handle message",
+		slog.String("id", msg.ID),
+		slog.Int("attempts", msg.Attempts),
+		slog.String("error", err.Error()),
+	)
+}
@@ -0,0 +1,147 @@
+package queues
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+type fakeMessage struct {
+	acked      bool
+	retried    bool
+	retryDelay int
+}
+
+func newFakeBatch(t *testing.T, bodies []any, attempts int) (js.Value, []*fakeMessage) {
+	t.Helper()
+	fakes := make([]*fakeMessage, len(bodies))
+	messages := jsutil.NewArray(len(bodies))
+	for i, body := range bodies {
+		fake := &fakeMessage{}
+		fakes[i] = fake
+		ack := js.FuncOf(func(js.Value, []js.Value) any {
+			fake.acked = true
+			return js.Undefined()
+		})
+		retry := js.FuncOf(func(_ js.Value, args []js.Value) any {
+			fake.retried = true
+			if len(args) > 0 && args[0].Type() == js.TypeObject {
+				fake.retryDelay = jsutil.MaybeInt(args[0].Get("delaySeconds"))
+			}
+			return js.Undefined()
+		})
+		t.Cleanup(func() {
+			ack.Release()
+			retry.Release()
+		})
+		obj := jsutil.NewObject()
+		obj.Set("id", "message-"+string(rune('a'+i)))
+		obj.Set("timestamp", jsutil.TimeToDate(time.Now()))
+		obj.Set("body", body)
+		obj.Set("attempts", attempts)
+		obj.Set("ack", ack)
+		obj.Set("retry", retry)
+		messages.SetIndex(i, obj)
+	}
+	batch := jsutil.NewObject()
+	batch.Set("queue", "my-queue")
+	batch.Set("messages", messages)
+	return batch, fakes
+}
+
+type task struct {
+	Name string `json:"name"`
+}
+
+func TestConsumeMessages(t *testing.T) {
+	t.Cleanup(func() { consumer = nil })
+	bytesBody := jsutil.NewUint8Array(len(`{"name":"bytes"}`))
+	js.CopyBytesToJS(bytesBody, []byte(`{"name":"bytes"}`))
+	batch, fakes := newFakeBatch(t, []any{
+		map[string]any{"name": "json"},
+		`{"name":"text"}`,
+		bytesBody,
+		map[string]any{"name": "fail"},
+		"not a json",
+	}, 3)
+
+	var mu sync.Mutex
+	var got []string
+	var errs []error
+	ConsumeMessagesNonBlock(func(ctx context.Context, body task, msg *Message) error {
+		if body.Name == "fail" {
+			return errors.New("failed")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, body.Name)
+		return nil
+	}, &ConsumeOptions{
+		Backoff:     ExponentialBackoff(10*time.Second, time.Minute),
+		Concurrency: 2,
+		OnError: func(msg *Message, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		},
+	})
+	if err := consumeBatch(batch, jsutil.NewObject()); err != nil {
+		t.Fatalf("consumeBatch failed: %v", err)
+	}
+
+	if len(got) != 3 {
+		t.Errorf("handled bodies = %v, want 3 bodies", got)
+	}
+	if len(errs) != 2 {
+		t.Errorf("errors = %v, want 2 errors", errs)
+	}
+	for i, fake := range fakes {
+		wantAcked := i < 3
+		if fake.acked != wantAcked || fake.retried == wantAcked {
+			t.Errorf("message %d: acked = %v, retried = %v, want acked = %v", i, fake.acked, fake.retried, wantAcked)
+		}
+		if !wantAcked && fake.retryDelay != 40 {
+			t.Errorf("message %d: retry delay = %d, want 40", i, fake.retryDelay)
+		}
+	}
+}
+
+func TestConsumeMessages_Panic(t *testing.T) {
+	t.Cleanup(func() { consumer = nil })
+	batch, fakes := newFakeBatch(t, []any{"hello"}, 1)
+	var gotErr error
+	ConsumeMessagesNonBlock(func(ctx context.Context, body string, msg *Message) error {
+		panic("broken")
+	}, &ConsumeOptions{
+		OnError: func(msg *Message, err error) { gotErr = err },
+	})
+	if err := consumeBatch(batch, jsutil.NewObject()); err != nil {
+		t.Fatalf("consumeBatch failed: %v", err)
+	}
+	if gotErr == nil {
+		t.Error("OnError was not called")
+	}
+	if !fakes[0].retried || fakes[0].retryDelay != 0 {
+		t.Errorf("retried = %v with delay %d, want retried without delay", fakes[0].retried, fakes[0].retryDelay)
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(time.Second, 5*time.Second)
+	for attempts, want := range map[int]time.Duration{
+		1: time.Second,
+		2: 2 * time.Second,
+		3: 4 * time.Second,
+		4: 5 * time.Second,
+		9: 5 * time.Second,
+	} {
+		if got := backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package queues
 
 import (
+	"context"
 	"fmt"
+	"reflect"
 	"syscall/js"
 
+	"github.com/syumai/workers/cloudflare/codec"
 	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
 	"github.com/syumai/workers/internal/jsutil"
 )
@@ -11,20 +14,93 @@ import (
 type Producer struct {
 	// queue - Objects that Queue API belongs to. Default is Global
 	queue js.Value
+	// codec - Codec to encode message bodies given to Send and NewMessageSendRequest. Default is codec.JSON
+	codec codec.Codec
 }
 
 // NewProducer creates a new Producer object to send messages to a queue.
 // queueName is the name of the queue environment var to send messages to.
 // In Cloudflare API documentation, this object represents the Queue.
 //   - https://developers.cloudflare.com/queues/configuration/javascript-apis/#producer
+//   - On an instance shared across events (workers-assets-gen -instance=shared), this function fails since the event is unknown. Use NewProducerFromContext instead.
 func NewProducer(queueName string) (*Producer, error) {
-	inst := cfruntimecontext.MustGetRuntimeContextEnv().Get(queueName)
+	return NewProducerFromContext(context.Background(), queueName)
+}
+
+// NewProducerFromContext creates a new Producer object to send messages to a queue
+// resolved from the runtime context attached to ctx.
+// If the runtime context is not attached to ctx, this function behaves the same as NewProducer.
+func NewProducerFromContext(ctx context.Context, queueName string) (*Producer, error) {
+	inst := cfruntimecontext.MustGetRuntimeContextEnvFromContext(ctx).Get(queueName)
 	if inst.IsUndefined() {
 		return nil, fmt.Errorf("%s is undefined", queueName)
 	}
 	return &Producer{queue: inst}, nil
 }
 
+// WithCodec returns a copy of the Producer which encodes message bodies given to Send and NewMessageSendRequest by c.
+//   - codec.JSON (default) sends bodies with the "json" content type, so JS consumers receive plain objects.
+//     String and byte slice types are sent with the "text" and "bytes" content types.
+//   - Other codecs send bodies with the "v8" content type.
+func (p *Producer) WithCodec(c codec.Codec) *Producer {
+	return &Producer{
+		queue: p.queue,
+		codec: c,
+	}
+}
+
+func (p *Producer) getCodec() codec.Codec {
+	if p.codec == nil {
+		return codec.JSON
+	}
+	return p.codec
+}
+
+// encode encodes v by the codec of the Producer, and returns the content type matching to the codec.
+//   - with codec.JSON, string and byte slice types (including named ones) are sent as text and bytes,
+//     so that Message.Decode decodes them symmetrically.
+func (p *Producer) encode(v any) (js.Value, contentType, error) {
+	c := p.getCodec()
+	if c == codec.JSON {
+		rv := reflect.ValueOf(v)
+		switch {
+		case rv.Kind() == reflect.String:
+			return js.ValueOf(rv.String()), contentTypeText, nil
+		case rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8:
+			b := rv.Bytes()
+			ua := jsutil.NewUint8Array(len(b))
+			js.CopyBytesToJS(ua, b)
+			return ua, contentTypeBytes, nil
+		}
+	}
+	body, err := c.Encode(v)
+	if err != nil {
+		return js.Value{}, "", fmt.Errorf("failed to encode message body: %w", err)
+	}
+	if c == codec.JSON {
+		return body, contentTypeJSON, nil
+	}
+	return body, contentTypeV8, nil
+}
+
+// Send sends a single message to a queue. v is encoded by the codec of the Producer (codec.JSON by default),
+// so any Go values including structs and typed slices can be sent.
+// The message can be decoded with Message.Decode on the consumer side.
+func (p *Producer) Send(ctx context.Context, v any, opts ...SendOption) error {
+	body, contentType, err := p.encode(v)
+	if err != nil {
+		return err
+	}
+	options := sendOptions{
+		ContentType: contentType,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	_, err = jsutil.AwaitPromiseContext(ctx, p.queue.Call("send", body, options.toJS()))
+	return err
+}
+
 // SendText sends a single text message to a queue.
 func (p *Producer) SendText(body string, opts ...SendOption) error {
 	return p.send(js.ValueOf(body), contentTypeText, opts...)
@@ -40,6 +116,7 @@ func (p *Producer) SendBytes(body []byte, opts ...SendOption) error {
 }
 
 // SendJSON sends a single JSON message to a queue.
+// body is passed to js.ValueOf as is, so it must not be a Go struct or a typed slice. Use Send for those values.
 func (p *Producer) SendJSON(body any, opts ...SendOption) error {
 	return p.send(js.ValueOf(body), contentTypeJSON, opts...)
 }
@@ -67,6 +144,7 @@ func (p *Producer) send(body js.Value, contentType contentType, opts ...SendOpti
 }
 
 // SendBatch sends multiple messages to a queue. This function allows setting options for each message.
+// Bodies of the messages created by NewMessageSendRequest are encoded by the codec of the Producer.
 func (p *Producer) SendBatch(messages []*MessageSendRequest, opts ...BatchSendOption) error {
 	var options batchSendOptions
 	for _, opt := range opts {
@@ -75,7 +153,11 @@ func (p *Producer) SendBatch(messages []*MessageSendRequest, opts ...BatchSendOp
 
 	jsArray := jsutil.NewArray(len(messages))
 	for i, message := range messages {
-		jsArray.SetIndex(i, message.toJS())
+		v, err := message.toJS(p)
+		if err != nil {
+			return fmt.Errorf("failed to encode message %d: %w", i, err)
+		}
+		jsArray.SetIndex(i, v)
 	}
 
 	prom := p.queue.Call("sendBatch", jsArray, options.toJS())
@@ -1,12 +1,15 @@
 package queues
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"syscall/js"
 	"testing"
 	"time"
 
+	"github.com/syumai/workers/cloudflare/codec"
 	"github.com/syumai/workers/internal/jsutil"
 )
 
@@ -138,3 +141,146 @@ func TestSendBatch_Options(t *testing.T) {
 		t.Fatalf("SendBatch failed: %v", err)
 	}
 }
+
+type order struct {
+	ID    int      `json:"id"`
+	Items []string `json:"items"`
+}
+
+func TestSend_Value(t *testing.T) {
+	var sent js.Value
+	validation := func(message js.Value, options js.Value) error {
+		sent = message
+		if message.Type() != js.TypeObject {
+			return errors.New("message body must be an object")
+		}
+		if options.Get("contentType").String() != "json" {
+			return errors.New("content type must be json")
+		}
+		return nil
+	}
+
+	producer := validatingProducer(t, validation)
+	want := order{ID: 1, Items: []string{"apple", "banana"}}
+	if err := producer.Send(context.Background(), want); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	var got order
+	if err := (&Message{Body: sent}).Decode(&got); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if got.ID != want.ID || len(got.Items) != 2 || got.Items[1] != "banana" {
+		t.Fatalf("Decode() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSend_WithCodec(t *testing.T) {
+	var sent js.Value
+	validation := func(message js.Value, options js.Value) error {
+		sent = message
+		if options.Get("contentType").String() != "v8" {
+			return errors.New("content type must be v8")
+		}
+		return nil
+	}
+
+	producer := validatingProducer(t, validation).WithCodec(codec.StructuredClone)
+	want := time.UnixMilli(1700000000000)
+	if err := producer.Send(context.Background(), want); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	var got time.Time
+	if err := (&Message{Body: sent}).DecodeWithCodec(codec.StructuredClone, &got); err != nil {
+		t.Fatalf("DecodeWithCodec failed: %v", err)
+	}
+	if !got.Equal(want) {
+		t.Fatalf("DecodeWithCodec() = %v, want %v", got, want)
+	}
+}
+
+func TestSendBatch_Values(t *testing.T) {
+	validation := func(batch js.Value, _ js.Value) error {
+		if batch.Length() != 2 {
+			return fmt.Errorf("expected 2 messages, got %d", batch.Length())
+		}
+		first := batch.Index(0)
+		if id := first.Get("body").Get("id").Int(); id != 1 {
+			return fmt.Errorf("first message id must be 1, was %d", id)
+		}
+		if first.Get("options").Get("contentType").String() != "json" {
+			return fmt.Errorf("first message content type must be json, was %s", first.Get("options").Get("contentType"))
+		}
+		if first.Get("options").Get("delaySeconds").Int() != 3 {
+			return fmt.Errorf("first message delay must be 3, was %s", first.Get("options").Get("delaySeconds"))
+		}
+		second := batch.Index(1)
+		if second.Get("options").Get("contentType").String() != "text" {
+			return fmt.Errorf("second message content type must be text, was %s", second.Get("options").Get("contentType"))
+		}
+		return nil
+	}
+
+	batch := []*MessageSendRequest{
+		NewMessageSendRequest(order{ID: 1}, WithDelaySeconds(3*time.Second)),
+		NewTextMessageSendRequest("world"),
+	}
+
+	producer := validatingProducer(t, validation)
+	if err := producer.SendBatch(batch); err != nil {
+		t.Fatalf("SendBatch failed: %v", err)
+	}
+}
+
+type myString string
+
+func TestSend_DecodeRoundTrip(t *testing.T) {
+	roundTrip := func(t *testing.T, v any, ptr any, wantContentType string) {
+		t.Helper()
+		var sent js.Value
+		producer := validatingProducer(t, func(message js.Value, options js.Value) error {
+			sent = message
+			if got := options.Get("contentType").String(); got != wantContentType {
+				return fmt.Errorf("content type = %s, want %s", got, wantContentType)
+			}
+			return nil
+		})
+		if err := producer.Send(context.Background(), v); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+		if err := (&Message{Body: sent}).Decode(ptr); err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+	}
+
+	t.Run("bytes", func(t *testing.T) {
+		var got []byte
+		roundTrip(t, []byte{1, 2, 3}, &got, "bytes")
+		if !reflect.DeepEqual(got, []byte{1, 2, 3}) {
+			t.Errorf("Decode() = %v, want [1 2 3]", got)
+		}
+	})
+	t.Run("string", func(t *testing.T) {
+		var got string
+		roundTrip(t, "hello", &got, "text")
+		if got != "hello" {
+			t.Errorf("Decode() = %q, want %q", got, "hello")
+		}
+	})
+	t.Run("named string", func(t *testing.T) {
+		var got myString
+		roundTrip(t, myString("x"), &got, "text")
+		if got != "x" {
+			t.Errorf("Decode() = %q, want %q", got, "x")
+		}
+	})
+	t.Run("struct", func(t *testing.T) {
+		var got order
+		want := order{ID: 1, Items: []string{"apple"}}
+		roundTrip(t, want, &got, "json")
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Decode() = %+v, want %+v", got, want)
+		}
+	})
+}
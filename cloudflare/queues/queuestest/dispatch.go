@@ -0,0 +1,158 @@
+package queuestest
+
+import (
+	"context"
+	"sync"
+	"syscall/js"
+	"time"
+
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+// decision is how a message was settled by the consumer.
+type decision int
+
+const (
+	undecided decision = iota
+	acked
+	retried
+)
+
+// delivery holds the state of a message during a batch delivery.
+type delivery struct {
+	msg      *Message
+	decision decision
+	delay    time.Duration
+}
+
+// Dispatch delivers a batch of the available messages to the registered Consumer, and returns the number of
+// delivered messages and the error returned from the Consumer.
+// After the delivery, messages are settled in the same way as Cloudflare Queues:
+//   - Messages acknowledged or retried individually by Message.Ack / Message.Retry follow the call.
+//   - Otherwise, MessageBatch.AckAll / MessageBatch.RetryAll are applied.
+//   - Otherwise, messages are acknowledged if the Consumer succeeded and retried if it failed.
+//
+// Retried messages exceeding MaxRetries are routed to the dead-letter queue.
+// The runtime context attached to ctx by NewContext is given to the Consumer.
+// If ctx is done before the Consumer completes, the batch is settled as failed with ctx.Err(),
+// and acknowledgements and retries called by the Consumer afterwards are ignored.
+func (q *Queue) Dispatch(ctx context.Context) (int, error) {
+	messages := q.takeBatch()
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	deliveries := make([]*delivery, len(messages))
+	messagesObj := jsutil.NewArray(len(messages))
+	// the Consumer may still settle messages after ctx is done.
+	// Such calls are ignored since the batch has been already settled.
+	var (
+		mu      sync.Mutex
+		settled bool
+		funcs   []js.Func
+	)
+	newFunc := func(fn func(args []js.Value)) js.Func {
+		f := js.FuncOf(func(_ js.Value, args []js.Value) any {
+			mu.Lock()
+			defer mu.Unlock()
+			if !settled {
+				fn(args)
+			}
+			return js.Undefined()
+		})
+		funcs = append(funcs, f)
+		return f
+	}
+
+	for i, m := range messages {
+		m.Attempts++
+		d := &delivery{msg: m}
+		deliveries[i] = d
+		obj := jsutil.NewObject()
+		obj.Set("id", m.ID)
+		obj.Set("timestamp", jsutil.TimeToDate(m.Timestamp))
+		obj.Set("body", m.Body)
+		obj.Set("attempts", m.Attempts)
+		obj.Set("ack", newFunc(func([]js.Value) {
+			d.decision = acked
+		}))
+		obj.Set("retry", newFunc(func(args []js.Value) {
+			d.decision = retried
+			d.delay = q.retryDelay(optionAt(args, 0))
+		}))
+		messagesObj.SetIndex(i, obj)
+	}
+
+	batchDecision, batchDelay := undecided, time.Duration(0)
+	batchObj := jsutil.NewObject()
+	batchObj.Set("queue", q.name)
+	batchObj.Set("messages", messagesObj)
+	batchObj.Set("ackAll", newFunc(func([]js.Value) {
+		batchDecision = acked
+	}))
+	batchObj.Set("retryAll", newFunc(func(args []js.Value) {
+		batchDecision = retried
+		batchDelay = q.retryDelay(optionAt(args, 0))
+	}))
+
+	runtimeObj, ok := runtimecontext.ExtractRuntimeObj(ctx)
+	if !ok {
+		runtimeObj = jsutil.NewObject()
+	}
+	p := jsutil.Binding.Call("handleQueueMessageBatch", batchObj, runtimeObj)
+	// the funcs are released after the Consumer completes, since it may call them after ctx is done.
+	var release js.Func
+	release = js.FuncOf(func(js.Value, []js.Value) any {
+		for _, f := range funcs {
+			f.Release()
+		}
+		release.Release()
+		return js.Undefined()
+	})
+	p.Call("then", release, release)
+	_, consumerErr := jsutil.AwaitPromiseContext(ctx, p)
+
+	mu.Lock()
+	settled = true
+	mu.Unlock()
+	for _, d := range deliveries {
+		if d.decision == undecided {
+			switch {
+			case batchDecision != undecided:
+				d.decision, d.delay = batchDecision, batchDelay
+			case consumerErr != nil:
+				d.decision, d.delay = retried, q.opts.RetryDelay
+			default:
+				d.decision = acked
+			}
+		}
+		if d.decision == retried {
+			q.retry(d.msg, d.delay)
+		}
+	}
+	return len(messages), consumerErr
+}
+
+// Drain dispatches batches until no messages are available at the current virtual time.
+// Errors returned from the Consumer are handled as retries and are not returned.
+// Messages retried with a delay are left in the Queue until the clock is advanced by Advance.
+func (q *Queue) Drain(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, _ := q.Dispatch(ctx)
+		if n == 0 {
+			return nil
+		}
+	}
+}
+
+// retryDelay returns the delay given as the retry options, or RetryDelay of the Queue.
+func (q *Queue) retryDelay(options js.Value) time.Duration {
+	if !options.Truthy() || !options.Get("delaySeconds").Truthy() {
+		return q.opts.RetryDelay
+	}
+	return delayOf(options)
+}
@@ -0,0 +1,207 @@
+// Package queuestest provides an in-memory Cloudflare Queues emulator for tests.
+//
+// A Queue is bound to a context by NewContext, so that queues.NewProducerFromContext enqueues messages into it.
+// Queue.Dispatch delivers the enqueued messages to the Consumer registered by queues.ConsumeNonBlock or
+// queues.ConsumeMessagesNonBlock, and handles acknowledgements, retries and dead-letter routing as Cloudflare Queues does.
+// Time is virtual: delayed messages become available after Queue.Advance.
+package queuestest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"syscall/js"
+	"time"
+
+	// queues registers the binding which Dispatch delivers batches to.
+	_ "github.com/syumai/workers/cloudflare/queues"
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+const (
+	defaultMaxBatchSize = 10
+	defaultMaxRetries   = 3
+)
+
+// Options configures a Queue. Zero values are replaced with the defaults of Cloudflare Queues.
+//   - https://developers.cloudflare.com/queues/configuration/configure-queues/#consumer
+type Options struct {
+	// MaxBatchSize is the maximum number of messages delivered in a batch. Default is 10.
+	MaxBatchSize int
+	// MaxRetries is the maximum number of retries of a message. Default is 3.
+	// Set a negative value to disable retries.
+	MaxRetries int
+	// RetryDelay is the delay before a message is retried when no delay is given by the consumer. Default is 0.
+	RetryDelay time.Duration
+	// DeadLetterQueue receives messages which exceeded MaxRetries.
+	// If DeadLetterQueue is nil, those messages are discarded.
+	DeadLetterQueue *Queue
+}
+
+// Message is a message held by a Queue.
+type Message struct {
+	ID        string
+	Timestamp time.Time
+	// Body is the message body as the consumer receives it.
+	Body js.Value
+	// Attempts is the number of times the message has been delivered.
+	Attempts int
+	// availableAt is the time when the message becomes available to be delivered.
+	availableAt time.Time
+}
+
+// Queue is an in-memory queue.
+type Queue struct {
+	name string
+	opts Options
+
+	mu       sync.Mutex
+	now      time.Time
+	seq      int
+	messages []*Message
+}
+
+// NewQueue returns a new Queue with the given name. opts can be nil.
+func NewQueue(name string, opts *Options) *Queue {
+	q := &Queue{
+		name: name,
+		now:  time.Now(),
+	}
+	if opts != nil {
+		q.opts = *opts
+	}
+	if q.opts.MaxBatchSize <= 0 {
+		q.opts.MaxBatchSize = defaultMaxBatchSize
+	}
+	if q.opts.MaxRetries == 0 {
+		q.opts.MaxRetries = defaultMaxRetries
+	}
+	return q
+}
+
+// Name returns the name of the Queue.
+func (q *Queue) Name() string {
+	return q.name
+}
+
+// Advance moves the virtual clock of the Queue forward by d.
+func (q *Queue) Advance(d time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.now = q.now.Add(d)
+}
+
+// Messages returns the messages held by the Queue, including delayed ones.
+func (q *Queue) Messages() []*Message {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	messages := make([]*Message, len(q.messages))
+	for i, m := range q.messages {
+		c := *m
+		messages[i] = &c
+	}
+	return messages
+}
+
+// Len returns the number of messages held by the Queue, including delayed ones.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.messages)
+}
+
+// enqueue adds a message with body to the Queue.
+func (q *Queue) enqueue(body js.Value, delay time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.seq++
+	q.messages = append(q.messages, &Message{
+		ID:          fmt.Sprintf("%s-%d", q.name, q.seq),
+		Timestamp:   q.now,
+		Body:        js.Global().Call("structuredClone", body),
+		Attempts:    0,
+		availableAt: q.now.Add(delay),
+	})
+}
+
+// takeBatch removes at most MaxBatchSize available messages from the Queue.
+func (q *Queue) takeBatch() []*Message {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var batch, rest []*Message
+	for _, m := range q.messages {
+		if len(batch) < q.opts.MaxBatchSize && !m.availableAt.After(q.now) {
+			batch = append(batch, m)
+			continue
+		}
+		rest = append(rest, m)
+	}
+	q.messages = rest
+	return batch
+}
+
+// retry puts the message back to the Queue, or routes it to the dead-letter queue if it exceeded MaxRetries.
+func (q *Queue) retry(m *Message, delay time.Duration) {
+	if m.Attempts > q.opts.MaxRetries {
+		if dlq := q.opts.DeadLetterQueue; dlq != nil {
+			dlq.enqueue(m.Body, 0)
+		}
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	m.availableAt = q.now.Add(delay)
+	q.messages = append(q.messages, m)
+}
+
+// NewContext returns a copy of ctx whose runtime context binds the queues to their variable names.
+// Producers created by queues.NewProducerFromContext with the returned context enqueue messages into the queues.
+func NewContext(ctx context.Context, queues map[string]*Queue) context.Context {
+	env := jsutil.NewObject()
+	for varName, q := range queues {
+		env.Set(varName, q.binding())
+	}
+	runtimeObj := jsutil.NewObject()
+	runtimeObj.Set("env", env)
+	return runtimecontext.WithRuntimeObj(ctx, runtimeObj)
+}
+
+// binding returns the Queue binding object used by Producer.
+//   - https://developers.cloudflare.com/queues/configuration/javascript-apis/#producer
+func (q *Queue) binding() js.Value {
+	obj := jsutil.NewObject()
+	obj.Set("send", js.FuncOf(func(_ js.Value, args []js.Value) any {
+		q.enqueue(args[0], delayOf(optionAt(args, 1)))
+		return jsutil.PromiseClass.Call("resolve")
+	}))
+	obj.Set("sendBatch", js.FuncOf(func(_ js.Value, args []js.Value) any {
+		messages := args[0]
+		batchDelay := delayOf(optionAt(args, 1))
+		for i := 0; i < messages.Length(); i++ {
+			m := messages.Index(i)
+			delay := batchDelay
+			if options := m.Get("options"); options.Truthy() && options.Get("delaySeconds").Truthy() {
+				delay = delayOf(options)
+			}
+			q.enqueue(m.Get("body"), delay)
+		}
+		return jsutil.PromiseClass.Call("resolve")
+	}))
+	return obj
+}
+
+func optionAt(args []js.Value, i int) js.Value {
+	if len(args) <= i {
+		return js.Undefined()
+	}
+	return args[i]
+}
+
+// delayOf returns the delay given as delaySeconds of options.
+func delayOf(options js.Value) time.Duration {
+	if !options.Truthy() || !options.Get("delaySeconds").Truthy() {
+		return 0
+	}
+	return time.Duration(options.Get("delaySeconds").Int()) * time.Second
+}
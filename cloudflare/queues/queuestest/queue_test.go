@@ -0,0 +1,204 @@
+package queuestest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/queues"
+	"github.com/syumai/workers/cloudflare/queues/queuestest"
+)
+
+type job struct {
+	Name string `json:"name"`
+}
+
+func newProducer(t *testing.T, ctx context.Context, varName string) *queues.Producer {
+	t.Helper()
+	p, err := queues.NewProducerFromContext(ctx, varName)
+	if err != nil {
+		t.Fatalf("NewProducerFromContext failed: %v", err)
+	}
+	return p
+}
+
+func TestQueue_ProduceConsumeDeadLetter(t *testing.T) {
+	dlq := queuestest.NewQueue("jobs-dlq", nil)
+	q := queuestest.NewQueue("jobs", &queuestest.Options{
+		MaxBatchSize:    2,
+		MaxRetries:      2,
+		DeadLetterQueue: dlq,
+	})
+	ctx := queuestest.NewContext(context.Background(), map[string]*queuestest.Queue{"JOBS": q})
+	p := newProducer(t, ctx, "JOBS")
+	for _, name := range []string{"a", "bad", "c"} {
+		if err := p.Send(ctx, job{Name: name}); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+	}
+
+	attempts := map[string]int{}
+	queues.ConsumeMessagesNonBlock(func(ctx context.Context, body job, msg *queues.Message) error {
+		attempts[body.Name] = msg.Attempts
+		if body.Name == "bad" {
+			return errors.New("failed")
+		}
+		return nil
+	}, &queues.ConsumeOptions{OnError: func(*queues.Message, error) {}})
+
+	n, err := q.Dispatch(ctx)
+	if err != nil {
+		t.Fatalf("Dispatch failed: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("Dispatch() = %d, want 2 (MaxBatchSize)", n)
+	}
+	if err := q.Drain(ctx); err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+
+	if attempts["a"] != 1 || attempts["c"] != 1 {
+		t.Errorf("attempts = %v, want 1 for a and c", attempts)
+	}
+	if attempts["bad"] != 3 {
+		t.Errorf("attempts of bad = %d, want 3", attempts["bad"])
+	}
+	if q.Len() != 0 {
+		t.Errorf("Len() = %d, want 0", q.Len())
+	}
+	dead := dlq.Messages()
+	if len(dead) != 1 {
+		t.Fatalf("len(dlq.Messages()) = %d, want 1", len(dead))
+	}
+	if name := dead[0].Body.Get("name").String(); name != "bad" {
+		t.Errorf("dead letter body name = %q, want %q", name, "bad")
+	}
+}
+
+func TestQueue_RetryDelay(t *testing.T) {
+	q := queuestest.NewQueue("jobs", &queuestest.Options{RetryDelay: time.Minute})
+	ctx := queuestest.NewContext(context.Background(), map[string]*queuestest.Queue{"JOBS": q})
+	p := newProducer(t, ctx, "JOBS")
+	if err := p.SendText("hello"); err != nil {
+		t.Fatalf("SendText failed: %v", err)
+	}
+	if err := p.SendText("delayed", queues.WithDelaySeconds(30*time.Second)); err != nil {
+		t.Fatalf("SendText failed: %v", err)
+	}
+
+	var delivered []string
+	queues.ConsumeNonBlock(func(batch *queues.MessageBatch) error {
+		for _, msg := range batch.Messages {
+			body, err := msg.StringBody()
+			if err != nil {
+				return err
+			}
+			delivered = append(delivered, body)
+			if body == "hello" && msg.Attempts == 1 {
+				msg.Retry(queues.WithRetryDelay(10 * time.Second))
+			}
+		}
+		return nil
+	})
+
+	steps := []struct {
+		advance time.Duration
+		want    int
+	}{
+		{0, 1},                // hello
+		{10 * time.Second, 1}, // retried hello
+		{20 * time.Second, 1}, // delayed
+		{time.Hour, 0},
+	}
+	for i, step := range steps {
+		q.Advance(step.advance)
+		n, err := q.Dispatch(ctx)
+		if err != nil {
+			t.Fatalf("step %d: Dispatch failed: %v", i, err)
+		}
+		if n != step.want {
+			t.Fatalf("step %d: Dispatch() = %d, want %d", i, n, step.want)
+		}
+	}
+	want := []string{"hello", "hello", "delayed"}
+	if len(delivered) != len(want) || delivered[0] != want[0] || delivered[1] != want[1] || delivered[2] != want[2] {
+		t.Errorf("delivered = %v, want %v", delivered, want)
+	}
+}
+
+func TestQueue_BatchError(t *testing.T) {
+	q := queuestest.NewQueue("jobs", &queuestest.Options{RetryDelay: time.Minute})
+	ctx := queuestest.NewContext(context.Background(), map[string]*queuestest.Queue{"JOBS": q})
+	p := newProducer(t, ctx, "JOBS")
+	if err := p.SendBatch([]*queues.MessageSendRequest{
+		queues.NewTextMessageSendRequest("acked"),
+		queues.NewTextMessageSendRequest("failed"),
+	}); err != nil {
+		t.Fatalf("SendBatch failed: %v", err)
+	}
+
+	wantErr := errors.New("failed")
+	queues.ConsumeNonBlock(func(batch *queues.MessageBatch) error {
+		batch.Messages[0].Ack()
+		return wantErr
+	})
+	if _, err := q.Dispatch(ctx); err == nil {
+		t.Fatal("Dispatch() error = nil, want error")
+	}
+	messages := q.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("len(Messages()) = %d, want 1", len(messages))
+	}
+	if body := messages[0].Body.String(); body != "failed" {
+		t.Errorf("retried body = %q, want %q", body, "failed")
+	}
+	if n, _ := q.Dispatch(ctx); n != 0 {
+		t.Errorf("Dispatch() before retry delay = %d, want 0", n)
+	}
+}
+
+func TestQueue_DispatchCanceled(t *testing.T) {
+	q := queuestest.NewQueue("jobs", nil)
+	ctx := queuestest.NewContext(context.Background(), map[string]*queuestest.Queue{"JOBS": q})
+	p := newProducer(t, ctx, "JOBS")
+	if err := p.SendText("slow"); err != nil {
+		t.Fatalf("SendText failed: %v", err)
+	}
+
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	done := make(chan struct{})
+	var ackPanic any
+	queues.ConsumeNonBlock(func(batch *queues.MessageBatch) error {
+		defer close(done)
+		close(started)
+		<-unblock
+		// the batch has been settled already, so this must be ignored without panicking.
+		defer func() { ackPanic = recover() }()
+		batch.Messages[0].Ack()
+		return nil
+	})
+
+	dispatchCtx, cancel := context.WithCancel(ctx)
+	go func() {
+		<-started
+		cancel()
+	}()
+	if _, err := q.Dispatch(dispatchCtx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Dispatch() error = %v, want %v", err, context.Canceled)
+	}
+	close(unblock)
+	<-done
+	if ackPanic != nil {
+		t.Fatalf("Ack() after cancellation panicked: %v", ackPanic)
+	}
+
+	messages := q.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("len(Messages()) = %d, want 1", len(messages))
+	}
+	if body := messages[0].Body.String(); body != "slow" {
+		t.Errorf("retried body = %q, want %q", body, "slow")
+	}
+}
@@ -1,6 +1,7 @@
 package r2
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"syscall/js"
@@ -21,8 +22,17 @@ type Bucket struct {
 //   - see example: https://github.com/syumai/workers/tree/main/_examples/r2-image-viewer
 //   - if the given variable name doesn't exist on runtime context, returns error.
 //   - This function panics when a runtime context is not found.
+//   - On an instance shared across events (workers-assets-gen -instance=shared), this function fails since the event is unknown. Use NewBucketFromContext instead.
 func NewBucket(varName string) (*Bucket, error) {
-	inst := cfruntimecontext.MustGetRuntimeContextEnv().Get(varName)
+	return NewBucketFromContext(context.Background(), varName)
+}
+
+// NewBucketFromContext returns Bucket for given variable name resolved from the runtime context attached to ctx.
+//   - if the runtime context is not attached to ctx, this function behaves the same as NewBucket.
+//   - if the given variable name doesn't exist on runtime context, returns error.
+//   - This function panics when a runtime context is not found.
+func NewBucketFromContext(ctx context.Context, varName string) (*Bucket, error) {
+	inst := cfruntimecontext.MustGetRuntimeContextEnvFromContext(ctx).Get(varName)
 	if inst.IsUndefined() {
 		return nil, fmt.Errorf("%s is undefined", varName)
 	}
@@ -93,19 +103,39 @@ func (opts *PutOptions) toJS() js.Value {
 }
 
 // Put returns the result of `put` call to Bucket.
-//   - This method copies all bytes into memory for implementation restriction.
+//   - if value is an unread incoming request body, its stream is forwarded to R2 without being copied into memory.
+//     The request must have Content-Length header in this case.
+//   - otherwise, this method copies all bytes into memory for implementation restriction. Use PutStream if the size is known.
 //   - Body field of *Object is always nil for Put call.
 //   - if a network error happens, returns error.
 func (r *Bucket) Put(key string, value io.ReadCloser, opts *PutOptions) (*Object, error) {
-	// fetch body cannot be ReadableStream. see: https://github.com/whatwg/fetch/issues/1438
-	b, err := io.ReadAll(value)
-	if err != nil {
-		return nil, err
-	}
+	return r.PutStream(key, value, -1, opts)
+}
+
+// PutStream returns the result of `put` call to Bucket with stream value of the given size in bytes.
+//   - if value is an unread incoming request body, its stream is forwarded to R2 without being copied into memory.
+//   - otherwise, value is streamed to R2 through FixedLengthStream without buffering the whole value in memory.
+//   - if size is negative or FixedLengthStream is not available, values other than an unread request body are copied into memory.
+//   - if the length of value does not match size, returns error.
+//   - Body field of *Object is always nil for Put call.
+//   - if a network error happens, returns error.
+func (r *Bucket) PutStream(key string, value io.ReadCloser, size int64, opts *PutOptions) (*Object, error) {
 	defer value.Close()
-	ua := jsutil.NewUint8Array(len(b))
-	js.CopyBytesToJS(ua, b)
-	p := r.instance.Call("put", key, ua.Get("buffer"), opts.toJS())
+	body, ok := jsutil.ConvertReaderToStreamBody(value, size)
+	if !ok {
+		// fetch body cannot be ReadableStream. see: https://github.com/whatwg/fetch/issues/1438
+		b, err := io.ReadAll(value)
+		if err != nil {
+			return nil, err
+		}
+		if size >= 0 && int64(len(b)) != size {
+			return nil, fmt.Errorf("size of value is %d bytes, want %d bytes", len(b), size)
+		}
+		ua := jsutil.NewUint8Array(len(b))
+		js.CopyBytesToJS(ua, b)
+		body = ua.Get("buffer")
+	}
+	p := r.instance.Call("put", key, body, opts.toJS())
 	v, err := jsutil.AwaitPromise(p)
 	if err != nil {
 		return nil, err
@@ -123,10 +153,53 @@ func (r *Bucket) Delete(key string) error {
 	return nil
 }
 
+// ListOptions represents Cloudflare R2 list options.
+//   - https://developers.cloudflare.com/r2/api/workers/workers-api-reference/#r2listoptions
+type ListOptions struct {
+	// Limit is the number of results to return. Default and maximum is 1000.
+	Limit  int
+	Prefix string
+	// Cursor is the cursor returned by the previous List call.
+	Cursor string
+	// Delimiter groups keys by the character. Grouped keys are returned as DelimitedPrefixes of Objects.
+	Delimiter string
+	// StartAfter lists keys lexicographically after the key.
+	StartAfter string
+}
+
+func (opts *ListOptions) toJS() js.Value {
+	if opts == nil {
+		return js.Undefined()
+	}
+	obj := jsutil.NewObject()
+	if opts.Limit != 0 {
+		obj.Set("limit", opts.Limit)
+	}
+	if opts.Prefix != "" {
+		obj.Set("prefix", opts.Prefix)
+	}
+	if opts.Cursor != "" {
+		obj.Set("cursor", opts.Cursor)
+	}
+	if opts.Delimiter != "" {
+		obj.Set("delimiter", opts.Delimiter)
+	}
+	if opts.StartAfter != "" {
+		obj.Set("startAfter", opts.StartAfter)
+	}
+	return obj
+}
+
 // List returns the result of `list` call to Bucket.
 //   - if a network error happens, returns error.
 func (r *Bucket) List() (*Objects, error) {
-	p := r.instance.Call("list")
+	return r.ListWithOptions(nil)
+}
+
+// ListWithOptions returns the result of `list` call to Bucket with options.
+//   - if a network error happens, returns error.
+func (r *Bucket) ListWithOptions(opts *ListOptions) (*Objects, error) {
+	p := r.instance.Call("list", opts.toJS())
 	v, err := jsutil.AwaitPromise(p)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,49 @@
+package r2
+
+import (
+	"io"
+	"strings"
+	"syscall/js"
+	"testing"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+func TestBucket_PutStream(t *testing.T) {
+	b, objects := newFakeBucket(t)
+	var putValues []js.Value
+	put := b.instance.Get("put")
+	record := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		putValues = append(putValues, args[1])
+		return put.Call("call", b.instance, args[0], args[1], args[2])
+	})
+	defer record.Release()
+	b.instance.Set("put", record)
+
+	rawBody := jsutil.ResponseClass.New("from request").Get("body")
+	requestBody := jsutil.ConvertReadableStreamToReadCloser(rawBody)
+	if _, err := b.Put("request", requestBody, nil); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if !putValues[0].Equal(rawBody) {
+		t.Error("the raw stream of the unread request body was not forwarded to put")
+	}
+	obj, err := b.PutStream("sized", io.NopCloser(strings.NewReader("sized")), 5, nil)
+	if err != nil {
+		t.Fatalf("PutStream failed: %v", err)
+	}
+	if obj.Key != "sized" || obj.Size != 5 {
+		t.Errorf("PutStream() = %s (%d bytes), want sized (5 bytes)", obj.Key, obj.Size)
+	}
+	if _, err := b.PutStream("mismatch", io.NopCloser(strings.NewReader("mismatch")), 3, nil); err == nil {
+		t.Error("PutStream() with mismatched size succeeded, want error")
+	}
+	for key, want := range map[string]string{
+		"request": "from request",
+		"sized":   "sized",
+	} {
+		if got := string(objects[key]); got != want {
+			t.Errorf("objects[%s] = %q, want %q", key, got, want)
+		}
+	}
+}
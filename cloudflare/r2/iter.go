@@ -0,0 +1,40 @@
+package r2
+
+import (
+	"context"
+	"iter"
+)
+
+// Objects returns an iterator over the objects listed with opts.
+//   - pages of ListWithOptions are fetched transparently while the iteration continues.
+//   - opts.Cursor is used as the starting cursor. opts is not modified.
+//   - DelimitedPrefixes are not yielded. Use ListWithOptions to get them.
+//   - if an error happens, the error is yielded with a nil object and the iteration stops.
+func (r *Bucket) Objects(ctx context.Context, opts *ListOptions) iter.Seq2[*Object, error] {
+	return func(yield func(*Object, error) bool) {
+		var o ListOptions
+		if opts != nil {
+			o = *opts
+		}
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+			result, err := r.ListWithOptions(&o)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, obj := range result.Objects {
+				if !yield(obj, nil) {
+					return
+				}
+			}
+			if !result.Truncated || result.Cursor == "" {
+				return
+			}
+			o.Cursor = result.Cursor
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package r2
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestBucket_Objects(t *testing.T) {
+	b, objects := newFakeBucket(t)
+	for _, key := range []string{"logs/1", "logs/2", "logs/3", "logs/4", "logs/5", "images/1"} {
+		objects[key] = []byte("v")
+	}
+
+	var got []string
+	for obj, err := range b.Objects(context.Background(), &ListOptions{Prefix: "logs/"}) {
+		if err != nil {
+			t.Fatalf("Objects failed: %v", err)
+		}
+		got = append(got, obj.Key)
+	}
+	want := []string{"logs/1", "logs/2", "logs/3", "logs/4", "logs/5"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Objects() = %v, want %v", got, want)
+	}
+
+	got = nil
+	for obj, err := range b.Objects(context.Background(), nil) {
+		if err != nil {
+			t.Fatalf("Objects failed: %v", err)
+		}
+		got = append(got, obj.Key)
+		if len(got) == 3 {
+			break
+		}
+	}
+	if len(got) != 3 {
+		t.Errorf("len(Objects()) after break = %d, want 3", len(got))
+	}
+}
@@ -0,0 +1,87 @@
+package r2
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// fakePageSize is the number of objects listed in a page by the fake bucket.
+const fakePageSize = 2
+
+// newFakeBucket returns a Bucket backed by an in-memory JS R2 bucket.
+func newFakeBucket(t *testing.T) (*Bucket, map[string][]byte) {
+	t.Helper()
+	objects := map[string][]byte{}
+	toObjectJS := func(key string) js.Value {
+		obj := jsutil.NewObject()
+		obj.Set("key", key)
+		obj.Set("version", "1")
+		obj.Set("size", len(objects[key]))
+		obj.Set("etag", "etag")
+		obj.Set("httpEtag", `"etag"`)
+		obj.Set("uploaded", jsutil.TimeToDate(time.Now()))
+		return obj
+	}
+	funcs := map[string]js.Func{
+		"put": js.FuncOf(func(_ js.Value, args []js.Value) any {
+			key := args[0].String()
+			// ArrayBuffer and ReadableStream values are read through Response.
+			var store js.Func
+			store = js.FuncOf(func(_ js.Value, pArgs []js.Value) any {
+				defer store.Release()
+				ua := jsutil.Uint8ArrayClass.New(pArgs[0])
+				b := make([]byte, ua.Get("byteLength").Int())
+				js.CopyBytesToGo(b, ua)
+				objects[key] = b
+				return toObjectJS(key)
+			})
+			return jsutil.ResponseClass.New(args[1]).Call("arrayBuffer").Call("then", store)
+		}),
+		"list": js.FuncOf(func(_ js.Value, args []js.Value) any {
+			var prefix string
+			var start int
+			if len(args) > 0 && args[0].Type() == js.TypeObject {
+				prefix = jsutil.MaybeString(args[0].Get("prefix"))
+				if cursor := args[0].Get("cursor"); !cursor.IsUndefined() {
+					start, _ = strconv.Atoi(cursor.String())
+				}
+			}
+			keys := make([]string, 0, len(objects))
+			for key := range objects {
+				if strings.HasPrefix(key, prefix) {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			end := min(start+fakePageSize, len(keys))
+			objs := jsutil.NewArray(end - start)
+			for i, key := range keys[start:end] {
+				objs.SetIndex(i, toObjectJS(key))
+			}
+			result := jsutil.NewObject()
+			result.Set("objects", objs)
+			result.Set("delimitedPrefixes", jsutil.NewArray(0))
+			result.Set("truncated", end < len(keys))
+			if end < len(keys) {
+				result.Set("cursor", strconv.Itoa(end))
+			}
+			return jsutil.PromiseClass.Call("resolve", result)
+		}),
+	}
+	obj := jsutil.NewObject()
+	for name, fn := range funcs {
+		obj.Set(name, fn)
+	}
+	t.Cleanup(func() {
+		for _, fn := range funcs {
+			fn.Release()
+		}
+	})
+	return &Bucket{instance: obj}, objects
+}
@@ -0,0 +1,36 @@
+package cloudflare
+
+import (
+	"context"
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare/codec"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// callRPC calls the RPC method of target with args, and returns its result.
+//   - args are encoded by codec.StructuredClone, and the result is decoded by the same codec.
+//   - If ctx is done before the call completes, ctx.Err() is returned. The call itself is not cancelled.
+func callRPC(ctx context.Context, target js.Value, method string, args []any) (*codec.Value, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	jsArgs := make([]any, len(args))
+	for i, arg := range args {
+		v, err := codec.StructuredClone.Encode(arg)
+		if err != nil {
+			return nil, err
+		}
+		jsArgs[i] = v
+	}
+	p, err := jsutil.TryCatchCall(target, method, jsArgs...)
+	if err != nil {
+		return nil, err
+	}
+	// The result of RPC methods is always a Promise.
+	v, err := jsutil.AwaitPromiseContext(ctx, jsutil.PromiseClass.Call("resolve", p))
+	if err != nil {
+		return nil, err
+	}
+	return codec.NewValue(v, codec.StructuredClone), nil
+}
@@ -0,0 +1,88 @@
+package cloudflare
+
+import (
+	"context"
+	"strings"
+	"syscall/js"
+	"testing"
+)
+
+// newFakeService returns a service binding whose RPC methods are implemented in JS.
+var newFakeService = js.Global().Get("Function").New(`
+	return {
+		async greet(user) {
+			return { message: "hello " + user.name, bytes: user.avatar.length };
+		},
+		sum(a, b) {
+			return a + b;
+		},
+		fail() {
+			throw new Error("rpc failed");
+		},
+	};
+`)
+
+func TestService_Call(t *testing.T) {
+	svc := &Service{instance: newFakeService.Invoke()}
+	ctx := context.Background()
+
+	type user struct {
+		Name   string `json:"name"`
+		Avatar []byte `json:"avatar"`
+	}
+	v, err := svc.Call(ctx, "greet", user{Name: "alice", Avatar: []byte{1, 2, 3}})
+	if err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	var res struct {
+		Message string `json:"message"`
+		Bytes   int    `json:"bytes"`
+	}
+	if err := v.Decode(&res); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if res.Message != "hello alice" || res.Bytes != 3 {
+		t.Errorf("result = %+v, want {Message:hello alice Bytes:3}", res)
+	}
+
+	v, err = svc.Call(ctx, "sum", 1, 2)
+	if err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	var sum int
+	if err := v.Decode(&sum); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if sum != 3 {
+		t.Errorf("sum = %d, want 3", sum)
+	}
+
+	if _, err := svc.Call(ctx, "fail"); err == nil || !strings.Contains(err.Error(), "rpc failed") {
+		t.Errorf("Call() error = %v, want rpc failed", err)
+	}
+}
+
+// newFakeRPCStub returns an object which behaves like an RPC stub of workerd:
+// every property, including bind, call and apply, resolves as a remote method.
+var newFakeRPCStub = js.Global().Get("Function").New(`
+	const remote = (name) => new Proxy(function () {}, {
+		get: (_, prop) => remote(name + "." + String(prop)),
+		apply: async (_, __, args) => name + "(" + args.join(",") + ")",
+	});
+	return new Proxy({}, { get: (_, prop) => remote(String(prop)) });
+`)
+
+func TestService_Call_RPCStub(t *testing.T) {
+	svc := &Service{instance: newFakeRPCStub.Invoke()}
+	v, err := svc.Call(context.Background(), "greet", "alice")
+	if err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	var got string
+	if err := v.Decode(&got); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if want := "greet(alice)"; got != want {
+		t.Errorf("result = %q, want %q", got, want)
+	}
+}
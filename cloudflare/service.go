@@ -0,0 +1,59 @@
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare/codec"
+	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
+	"github.com/syumai/workers/internal/jshttp"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// Service represents a service binding to another Worker.
+//   - https://developers.cloudflare.com/workers/runtime-apis/bindings/service-bindings/
+type Service struct {
+	instance js.Value
+}
+
+// NewService returns the service binding for the `varName` binding.
+//
+// This binding must be defined in the `wrangler.toml` file. The method will
+// return an `error` when there is no binding defined by `varName`.
+//   - On an instance shared across events (workers-assets-gen -instance=shared), this function fails since the event is unknown. Use NewServiceFromContext instead.
+func NewService(varName string) (*Service, error) {
+	return NewServiceFromContext(context.Background(), varName)
+}
+
+// NewServiceFromContext returns the service binding for the `varName` binding
+// resolved from the runtime context attached to ctx.
+//
+// If the runtime context is not attached to ctx, this function behaves the same as
+// NewService.
+func NewServiceFromContext(ctx context.Context, varName string) (*Service, error) {
+	inst := cfruntimecontext.MustGetRuntimeContextEnvFromContext(ctx).Get(varName)
+	if inst.IsUndefined() {
+		return nil, fmt.Errorf("%s is undefined", varName)
+	}
+	return &Service{instance: inst}, nil
+}
+
+// Fetch sends the HTTP request to the `fetch()` handler of the Worker.
+func (s *Service) Fetch(req *http.Request) (*http.Response, error) {
+	jsRes, err := jsutil.AwaitPromise(s.instance.Call("fetch", jshttp.ToJSRequest(req)))
+	if err != nil {
+		return nil, err
+	}
+	return jshttp.ToResponse(jsRes)
+}
+
+// Call calls the RPC method of the `WorkerEntrypoint` bound to the service binding.
+//   - args are encoded by codec.StructuredClone, and the result can be decoded by Decode of the returned value.
+//   - If ctx is done before the call completes, ctx.Err() is returned. The call itself is not cancelled.
+//
+// https://developers.cloudflare.com/workers/runtime-apis/rpc/
+func (s *Service) Call(ctx context.Context, method string, args ...any) (*codec.Value, error) {
+	return callRPC(ctx, s.instance, method, args)
+}
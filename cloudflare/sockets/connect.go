@@ -29,8 +29,13 @@ type SocketOptions struct {
 
 const defaultDeadline = 999999 * time.Hour
 
+// Connect opens a TCP socket to addr.
+//   - The socket is closed when ctx is done.
 func Connect(ctx context.Context, addr string, opts *SocketOptions) (net.Conn, error) {
-	connect, err := cfruntimecontext.GetRuntimeContextValue("connect")
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	connect, err := cfruntimecontext.GetRuntimeContextValueFromContext(ctx, "connect")
 	if err != nil {
 		return nil, err
 	}
@@ -50,5 +55,9 @@ func Connect(ctx context.Context, addr string, opts *SocketOptions) (net.Conn, e
 		return nil, err
 	}
 	deadline := time.Now().Add(defaultDeadline)
-	return newSocket(ctx, sockVal, deadline, deadline), nil
+	sock := newSocket(ctx, sockVal, deadline, deadline)
+	sock.stopCloseOnDone = context.AfterFunc(ctx, func() {
+		sock.Close()
+	})
+	return sock, nil
 }
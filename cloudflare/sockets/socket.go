@@ -47,6 +47,9 @@ type Socket struct {
 	close      func()
 	closeRead  func()
 	closeWrite func()
+
+	// stopCloseOnDone stops closing the socket when the context given to Connect is done.
+	stopCloseOnDone func() bool
 }
 
 var _ net.Conn = (*Socket)(nil)
@@ -105,6 +108,9 @@ func (t *Socket) StartTLS() *Socket {
 // Any blocked Read or Write operations will be unblocked and return errors.
 func (t *Socket) Close() error {
 	defer t.cancel()
+	if t.stopCloseOnDone != nil {
+		t.stopCloseOnDone()
+	}
 	t.close()
 	return nil
 }
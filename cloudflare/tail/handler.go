@@ -0,0 +1,83 @@
+package tail
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"syscall/js"
+
+	"github.com/syumai/workers"
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/recovery"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+// Handler is a function which handles trace events of the producer Workers.
+type Handler func(ctx context.Context, items []*TraceItem) error
+
+var handler Handler
+
+// handleTail runs the Handler with the given trace events.
+// A panic in the Handler is recovered, reported and returned as an error.
+func handleTail(eventsObj, runtimeObj js.Value) (err error) {
+	if handler == nil {
+		return fmt.Errorf("tail handler is not set")
+	}
+	defer func() {
+		if v := recover(); v != nil {
+			recovery.Report(nil, v, debug.Stack())
+			err = recovery.Error(v)
+		}
+	}()
+	items, err := newTraceItems(eventsObj)
+	if err != nil {
+		return err
+	}
+	ctx := runtimecontext.New(context.Background(), eventsObj, runtimeObj)
+	return handler(ctx, items)
+}
+
+func init() {
+	handleTailCallback := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		if len(args) != 2 {
+			panic(fmt.Errorf("invalid number of arguments given to handleTail: %d", len(args)))
+		}
+		eventsObj := args[0]
+		runtimeObj := args[1]
+		var cb js.Func
+		cb = js.FuncOf(func(_ js.Value, pArgs []js.Value) any {
+			defer cb.Release()
+			resolve := pArgs[0]
+			reject := pArgs[1]
+			go func() {
+				if err := handleTail(eventsObj, runtimeObj); err != nil {
+					reject.Invoke(jsutil.Error(err.Error()))
+					return
+				}
+				resolve.Invoke(js.Undefined())
+			}()
+			return js.Undefined()
+		})
+		return jsutil.NewPromise(cb)
+	})
+	jsutil.Binding.Set("handleTail", handleTailCallback)
+}
+
+// Handle sets the Handler to receive trace events of the producer Workers.
+// NOTE: This function will block the current goroutine and is intended to be used as long as the
+// only worker's purpose is to be a Tail Worker.
+// In case the worker has other purposes (e.g. handling HTTP requests), use HandleNonBlock instead.
+//   - The tail handler must be exported from worker.mjs by workers-assets-gen with the `-tail` flag.
+//   - https://developers.cloudflare.com/workers/observability/logs/tail-workers/
+func Handle(h Handler) {
+	handler = h
+	workers.Ready()
+	select {}
+}
+
+// HandleNonBlock sets the Handler to receive trace events of the producer Workers.
+// HandleNonBlock should be called before setting other blocking handlers (e.g. workers.Serve).
+//   - The tail handler must be exported from worker.mjs by workers-assets-gen with the `-tail` flag.
+func HandleNonBlock(h Handler) {
+	handler = h
+}
@@ -0,0 +1,271 @@
+package tail
+
+import (
+	"fmt"
+	"net/http"
+	"syscall/js"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/codec"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// TraceItem represents a trace event of a single invocation of the producer Worker.
+//   - https://developers.cloudflare.com/workers/runtime-apis/handlers/tail/
+type TraceItem struct {
+	// ScriptName is the name of the producer Worker. It is empty if the name is not available.
+	ScriptName string
+	// DispatchNamespace is the dispatch namespace of the producer Worker for Workers for Platforms.
+	DispatchNamespace string
+	ScriptTags        []string
+	// Outcome is the outcome of the invocation (e.g. "ok", "exception", "exceededCpu", "canceled").
+	Outcome        string
+	EventTimestamp time.Time
+	// Event is the information of the event which invoked the producer Worker.
+	Event                    *Event
+	Logs                     []*Log
+	Exceptions               []*Exception
+	DiagnosticsChannelEvents []*DiagnosticsChannelEvent
+	Truncated                bool
+}
+
+// Event is the information of the event which invoked the producer Worker.
+// Only one of the fields is set, and all of them are nil for the event types not listed here.
+type Event struct {
+	Fetch     *FetchEventInfo
+	Scheduled *ScheduledEventInfo
+	Queue     *QueueEventInfo
+	Alarm     *AlarmEventInfo
+}
+
+// FetchEventInfo is the information of a fetch event.
+type FetchEventInfo struct {
+	Request *FetchEventRequest
+	// Response is nil if the producer Worker did not return a response.
+	Response *FetchEventResponse
+}
+
+// FetchEventRequest is the request received by the producer Worker.
+// Sensitive headers are redacted by the runtime.
+type FetchEventRequest struct {
+	URL    string
+	Method string
+	Header http.Header
+	// CF is the `cf` properties of the request.
+	CF map[string]any
+}
+
+// FetchEventResponse is the response returned by the producer Worker.
+type FetchEventResponse struct {
+	Status int
+}
+
+// ScheduledEventInfo is the information of a scheduled event.
+type ScheduledEventInfo struct {
+	Cron          string
+	ScheduledTime time.Time
+}
+
+// QueueEventInfo is the information of a queue event.
+type QueueEventInfo struct {
+	Queue     string
+	BatchSize int
+}
+
+// AlarmEventInfo is the information of a Durable Object alarm event.
+type AlarmEventInfo struct {
+	ScheduledTime time.Time
+}
+
+// Log is a message logged with console methods by the producer Worker.
+type Log struct {
+	Timestamp time.Time
+	// Level is the console method used to log (e.g. "log", "info", "error").
+	Level string
+	// Message is the arguments given to the console method.
+	// Arguments which can't be decoded (e.g. functions, bigints and symbols) are converted into strings.
+	Message []any
+}
+
+// Exception is an uncaught exception thrown by the producer Worker.
+type Exception struct {
+	Timestamp time.Time
+	Name      string
+	Message   string
+}
+
+// DiagnosticsChannelEvent is a message published to a diagnostics channel by the producer Worker.
+type DiagnosticsChannelEvent struct {
+	Timestamp time.Time
+	Channel   string
+	// Message is the published message. It is converted into a string if it can't be decoded.
+	Message any
+}
+
+func newTraceItems(v js.Value) ([]*TraceItem, error) {
+	items := make([]*TraceItem, v.Length())
+	for i := range items {
+		item, err := newTraceItem(v.Index(i))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse trace item at %d: %w", i, err)
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+func newTraceItem(v js.Value) (*TraceItem, error) {
+	event, err := newEvent(v.Get("event"))
+	if err != nil {
+		return nil, err
+	}
+	logsObj := v.Get("logs")
+	logs := make([]*Log, length(logsObj))
+	for i := range logs {
+		logObj := logsObj.Index(i)
+		messageObj := logObj.Get("message")
+		message := make([]any, length(messageObj))
+		for j := range message {
+			message[j] = decodeOrString(messageObj.Index(j))
+		}
+		logs[i] = &Log{
+			Timestamp: toTime(logObj.Get("timestamp")),
+			Level:     logObj.Get("level").String(),
+			Message:   message,
+		}
+	}
+	exceptionsObj := v.Get("exceptions")
+	exceptions := make([]*Exception, length(exceptionsObj))
+	for i := range exceptions {
+		exceptionObj := exceptionsObj.Index(i)
+		exceptions[i] = &Exception{
+			Timestamp: toTime(exceptionObj.Get("timestamp")),
+			Name:      nullableString(exceptionObj.Get("name")),
+			Message:   nullableString(exceptionObj.Get("message")),
+		}
+	}
+	dceObj := v.Get("diagnosticsChannelEvents")
+	dces := make([]*DiagnosticsChannelEvent, length(dceObj))
+	for i := range dces {
+		eventObj := dceObj.Index(i)
+		dces[i] = &DiagnosticsChannelEvent{
+			Timestamp: toTime(eventObj.Get("timestamp")),
+			Channel:   eventObj.Get("channel").String(),
+			Message:   decodeOrString(eventObj.Get("message")),
+		}
+	}
+	var scriptTags []string
+	if tagsObj := v.Get("scriptTags"); length(tagsObj) > 0 {
+		scriptTags = make([]string, length(tagsObj))
+		for i := range scriptTags {
+			scriptTags[i] = tagsObj.Index(i).String()
+		}
+	}
+	return &TraceItem{
+		ScriptName:               nullableString(v.Get("scriptName")),
+		DispatchNamespace:        nullableString(v.Get("dispatchNamespace")),
+		ScriptTags:               scriptTags,
+		Outcome:                  v.Get("outcome").String(),
+		EventTimestamp:           toTime(v.Get("eventTimestamp")),
+		Event:                    event,
+		Logs:                     logs,
+		Exceptions:               exceptions,
+		DiagnosticsChannelEvents: dces,
+		Truncated:                v.Get("truncated").Truthy(),
+	}, nil
+}
+
+func newEvent(v js.Value) (*Event, error) {
+	if v.IsUndefined() || v.IsNull() {
+		return nil, nil
+	}
+	switch {
+	case !v.Get("request").IsUndefined():
+		reqObj := v.Get("request")
+		var cf map[string]any
+		if cfObj := reqObj.Get("cf"); cfObj.Truthy() {
+			if err := decode(cfObj, &cf); err != nil {
+				return nil, fmt.Errorf("failed to decode cf properties: %w", err)
+			}
+		}
+		header := http.Header{}
+		for k, v := range jsutil.StrRecordToMap(reqObj.Get("headers")) {
+			header.Set(k, v)
+		}
+		info := &FetchEventInfo{
+			Request: &FetchEventRequest{
+				URL:    reqObj.Get("url").String(),
+				Method: reqObj.Get("method").String(),
+				Header: header,
+				CF:     cf,
+			},
+		}
+		if resObj := v.Get("response"); resObj.Truthy() {
+			info.Response = &FetchEventResponse{Status: resObj.Get("status").Int()}
+		}
+		return &Event{Fetch: info}, nil
+	case !v.Get("cron").IsUndefined():
+		return &Event{Scheduled: &ScheduledEventInfo{
+			Cron:          v.Get("cron").String(),
+			ScheduledTime: toTime(v.Get("scheduledTime")),
+		}}, nil
+	case !v.Get("queue").IsUndefined():
+		return &Event{Queue: &QueueEventInfo{
+			Queue:     v.Get("queue").String(),
+			BatchSize: jsutil.MaybeInt(v.Get("batchSize")),
+		}}, nil
+	case !v.Get("scheduledTime").IsUndefined():
+		return &Event{Alarm: &AlarmEventInfo{
+			ScheduledTime: toTime(v.Get("scheduledTime")),
+		}}, nil
+	}
+	return &Event{}, nil
+}
+
+// length returns the length of the array v, or 0 if v is not an array.
+func length(v js.Value) int {
+	if !jsutil.ArrayClass.Call("isArray", v).Bool() {
+		return 0
+	}
+	return v.Length()
+}
+
+// decode decodes v into ptr. ptr is left as is if v is undefined.
+func decode(v js.Value, ptr any) error {
+	if v.IsUndefined() {
+		return nil
+	}
+	return codec.StructuredClone.Decode(v, ptr)
+}
+
+// decodeOrString decodes v into a Go value for `any`.
+// If v can't be decoded, it is converted into a string in the same way as String(v) in JS,
+// so that a single unsupported value doesn't fail the whole trace item.
+func decodeOrString(v js.Value) any {
+	var x any
+	if err := decode(v, &x); err != nil {
+		return js.Global().Get("String").Invoke(v).String()
+	}
+	return x
+}
+
+// toTime converts the timestamp given as a Date or milliseconds since the epoch into time.Time.
+func toTime(v js.Value) time.Time {
+	switch v.Type() {
+	case js.TypeNumber:
+		return time.UnixMilli(int64(v.Float()))
+	case js.TypeObject:
+		if v.InstanceOf(jsutil.DateClass) {
+			t, _ := jsutil.DateToTime(v)
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func nullableString(v js.Value) string {
+	if v.IsUndefined() || v.IsNull() {
+		return ""
+	}
+	return v.String()
+}
@@ -0,0 +1,193 @@
+package tail
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+func TestNewTraceItems(t *testing.T) {
+	ts := time.UnixMilli(1700000000000)
+	events := js.ValueOf([]any{
+		map[string]any{
+			"scriptName":     "producer",
+			"outcome":        "ok",
+			"eventTimestamp": ts.UnixMilli(),
+			"event": map[string]any{
+				"request": map[string]any{
+					"url":     "https://example.com/",
+					"method":  "GET",
+					"headers": map[string]any{"user-agent": "test"},
+					"cf":      map[string]any{"colo": "NRT"},
+				},
+				"response": map[string]any{"status": 200},
+			},
+			"logs": []any{
+				map[string]any{
+					"timestamp": ts.UnixMilli(),
+					"level":     "log",
+					"message":   []any{"hello", 1},
+				},
+			},
+			"exceptions": []any{
+				map[string]any{
+					"timestamp": ts.UnixMilli(),
+					"name":      "Error",
+					"message":   "broken",
+				},
+			},
+			"diagnosticsChannelEvents": []any{
+				map[string]any{
+					"timestamp": ts.UnixMilli(),
+					"channel":   "my-channel",
+					"message":   map[string]any{"key": "value"},
+				},
+			},
+		},
+		map[string]any{
+			"scriptName":     nil,
+			"outcome":        "exception",
+			"eventTimestamp": ts.UnixMilli(),
+			"event": map[string]any{
+				"cron":          "*/5 * * * *",
+				"scheduledTime": ts.UnixMilli(),
+			},
+			"logs":                     []any{},
+			"exceptions":               []any{},
+			"diagnosticsChannelEvents": []any{},
+		},
+		map[string]any{
+			"scriptName": "consumer",
+			"outcome":    "ok",
+			"event": map[string]any{
+				"queue":     "my-queue",
+				"batchSize": 3,
+			},
+			"logs":                     []any{},
+			"exceptions":               []any{},
+			"diagnosticsChannelEvents": []any{},
+		},
+	})
+
+	items, err := newTraceItems(events)
+	if err != nil {
+		t.Fatalf("newTraceItems failed: %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("len(items) = %d, want 3", len(items))
+	}
+
+	fetchItem := items[0]
+	if fetchItem.ScriptName != "producer" {
+		t.Errorf("ScriptName = %q, want %q", fetchItem.ScriptName, "producer")
+	}
+	if !fetchItem.EventTimestamp.Equal(ts) {
+		t.Errorf("EventTimestamp = %v, want %v", fetchItem.EventTimestamp, ts)
+	}
+	fetch := fetchItem.Event.Fetch
+	if fetch == nil {
+		t.Fatal("Event.Fetch = nil, want non-nil")
+	}
+	if fetch.Request.URL != "https://example.com/" || fetch.Request.Method != "GET" {
+		t.Errorf("Request = %s %s, want GET https://example.com/", fetch.Request.Method, fetch.Request.URL)
+	}
+	if got := fetch.Request.Header.Get("User-Agent"); got != "test" {
+		t.Errorf("Header.Get(User-Agent) = %q, want %q", got, "test")
+	}
+	if got := fetch.Request.CF["colo"]; got != "NRT" {
+		t.Errorf("CF[colo] = %v, want %v", got, "NRT")
+	}
+	if fetch.Response == nil || fetch.Response.Status != 200 {
+		t.Errorf("Response = %+v, want status 200", fetch.Response)
+	}
+	if len(fetchItem.Logs) != 1 {
+		t.Fatalf("len(Logs) = %d, want 1", len(fetchItem.Logs))
+	}
+	if log := fetchItem.Logs[0]; log.Level != "log" || len(log.Message) != 2 || log.Message[0] != "hello" || log.Message[1] != float64(1) {
+		t.Errorf("Logs[0] = %+v, want log [hello 1]", log)
+	}
+	if len(fetchItem.Exceptions) != 1 || fetchItem.Exceptions[0].Message != "broken" {
+		t.Errorf("Exceptions = %+v, want one exception of broken", fetchItem.Exceptions)
+	}
+	if len(fetchItem.DiagnosticsChannelEvents) != 1 {
+		t.Fatalf("len(DiagnosticsChannelEvents) = %d, want 1", len(fetchItem.DiagnosticsChannelEvents))
+	}
+	dce := fetchItem.DiagnosticsChannelEvents[0]
+	if m, ok := dce.Message.(map[string]any); dce.Channel != "my-channel" || !ok || m["key"] != "value" {
+		t.Errorf("DiagnosticsChannelEvents[0] = %+v, want my-channel {key: value}", dce)
+	}
+
+	scheduledItem := items[1]
+	if scheduledItem.ScriptName != "" {
+		t.Errorf("ScriptName = %q, want empty", scheduledItem.ScriptName)
+	}
+	scheduled := scheduledItem.Event.Scheduled
+	if scheduled == nil || scheduled.Cron != "*/5 * * * *" || !scheduled.ScheduledTime.Equal(ts) {
+		t.Errorf("Event.Scheduled = %+v, want */5 * * * * at %v", scheduled, ts)
+	}
+
+	queue := items[2].Event.Queue
+	if queue == nil || queue.Queue != "my-queue" || queue.BatchSize != 3 {
+		t.Errorf("Event.Queue = %+v, want my-queue with 3 messages", queue)
+	}
+}
+
+func TestHandleTail(t *testing.T) {
+	events := js.ValueOf([]any{
+		map[string]any{"scriptName": "producer", "outcome": "ok"},
+	})
+	var got []*TraceItem
+	HandleNonBlock(func(ctx context.Context, items []*TraceItem) error {
+		got = items
+		return nil
+	})
+	defer HandleNonBlock(nil)
+	if err := handleTail(events, jsutil.NewObject()); err != nil {
+		t.Fatalf("handleTail failed: %v", err)
+	}
+	if len(got) != 1 || got[0].Outcome != "ok" || got[0].Event != nil {
+		t.Errorf("items = %+v, want one item with ok and no event", got)
+	}
+}
+
+func TestHandleTail_Error(t *testing.T) {
+	wantErr := errors.New("failed")
+	HandleNonBlock(func(ctx context.Context, items []*TraceItem) error {
+		return wantErr
+	})
+	defer HandleNonBlock(nil)
+	if err := handleTail(js.ValueOf([]any{}), jsutil.NewObject()); !errors.Is(err, wantErr) {
+		t.Fatalf("handleTail() error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestNewTraceItems_UndecodableValues(t *testing.T) {
+	newItem := js.Global().Get("Function").New(`
+		return [{
+			outcome: "exception",
+			logs: [{ level: "log", message: ["ok", () => {}, 10n, Symbol("sym")] }],
+			exceptions: [{ timestamp: 0 }],
+			diagnosticsChannelEvents: [{ channel: "my-channel", message: 20n }],
+		}];
+	`)
+	items, err := newTraceItems(newItem.Invoke())
+	if err != nil {
+		t.Fatalf("newTraceItems failed: %v", err)
+	}
+	item := items[0]
+	want := []any{"ok", "() => {}", "10", "Symbol(sym)"}
+	if got := item.Logs[0].Message; !reflect.DeepEqual(got, want) {
+		t.Errorf("Logs[0].Message = %#v, want %#v", got, want)
+	}
+	if e := item.Exceptions[0]; e.Name != "" || e.Message != "" {
+		t.Errorf("Exceptions[0] = %+v, want empty name and message", e)
+	}
+	if got := item.DiagnosticsChannelEvents[0].Message; got != "20" {
+		t.Errorf("DiagnosticsChannelEvents[0].Message = %#v, want %q", got, "20")
+	}
+}
@@ -0,0 +1,210 @@
+package websocket
+
+import (
+	"errors"
+	"sync"
+	"syscall/js"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+var (
+	// ErrCloseSent is returned when a message is written after the connection was closed.
+	ErrCloseSent = errors.New("websocket: close sent")
+	// ErrControlMessageNotSupported is returned when a ping or pong message is written.
+	// Cloudflare Workers runtime responds to pings automatically.
+	ErrControlMessageNotSupported = errors.New("websocket: ping and pong messages are handled by the runtime")
+)
+
+type message struct {
+	messageType MessageType
+	data        []byte
+}
+
+// Conn represents a WebSocket connection.
+//   - https://developers.cloudflare.com/workers/runtime-apis/websockets/
+//   - ReadMessage must not be called concurrently. WriteMessage can be called concurrently.
+type Conn struct {
+	ws js.Value
+
+	mu       sync.Mutex
+	queue    []message
+	notifyCh chan struct{}
+	// readErr is an error returned by ReadMessage after all queued messages are read.
+	readErr   error
+	closeSent bool
+	listeners map[string]js.Func
+}
+
+// newConn returns Conn which receives messages of the given WebSocket object.
+// The WebSocket must be accepted after calling this function.
+func newConn(ws js.Value) *Conn {
+	c := &Conn{
+		ws:       ws,
+		notifyCh: make(chan struct{}, 1),
+	}
+	c.listeners = map[string]js.Func{
+		"message": js.FuncOf(func(_ js.Value, args []js.Value) any {
+			c.onMessage(args[0])
+			return js.Undefined()
+		}),
+		"close": js.FuncOf(func(_ js.Value, args []js.Value) any {
+			c.onClose(args[0])
+			return js.Undefined()
+		}),
+		"error": js.FuncOf(func(_ js.Value, args []js.Value) any {
+			c.onError(args[0])
+			return js.Undefined()
+		}),
+	}
+	for typ, fn := range c.listeners {
+		ws.Call("addEventListener", typ, fn)
+	}
+	return c
+}
+
+// notify wakes up ReadMessage without blocking JS event listeners.
+func (c *Conn) notify() {
+	select {
+	case c.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+func (c *Conn) onMessage(event js.Value) {
+	data := event.Get("data")
+	var msg message
+	if data.Type() == js.TypeString {
+		msg = message{messageType: TextMessage, data: []byte(data.String())}
+	} else {
+		// data is ArrayBuffer.
+		ua := jsutil.Uint8ArrayClass.New(data)
+		b := make([]byte, ua.Get("byteLength").Int())
+		js.CopyBytesToGo(b, ua)
+		msg = message{messageType: BinaryMessage, data: b}
+	}
+	c.mu.Lock()
+	c.queue = append(c.queue, msg)
+	c.mu.Unlock()
+	c.notify()
+}
+
+func (c *Conn) onClose(event js.Value) {
+	closeErr := &CloseError{
+		Code: jsutil.MaybeInt(event.Get("code")),
+		Text: jsutil.MaybeString(event.Get("reason")),
+	}
+	c.mu.Lock()
+	if c.readErr == nil {
+		c.readErr = closeErr
+	}
+	closeSent := c.closeSent
+	c.closeSent = true
+	c.mu.Unlock()
+	if !closeSent {
+		// complete the closing handshake. errors are ignored since the socket may be already closed.
+		_ = c.close(closeErr.Code, closeErr.Text)
+	}
+	c.releaseListeners()
+	c.notify()
+}
+
+func (c *Conn) onError(event js.Value) {
+	msg := "websocket: unknown error"
+	if errVal := event.Get("error"); !errVal.IsUndefined() && !errVal.IsNull() {
+		msg = "websocket: " + errVal.Call("toString").String()
+	}
+	c.mu.Lock()
+	if c.readErr == nil {
+		c.readErr = errors.New(msg)
+	}
+	c.mu.Unlock()
+	c.notify()
+}
+
+func (c *Conn) releaseListeners() {
+	for typ, fn := range c.listeners {
+		c.ws.Call("removeEventListener", typ, fn)
+		fn.Release()
+	}
+	c.listeners = nil
+}
+
+// ReadMessage reads the next message from the connection.
+//   - messageType is either TextMessage or BinaryMessage.
+//   - After the peer closes the connection, *CloseError is returned.
+func (c *Conn) ReadMessage() (messageType MessageType, data []byte, err error) {
+	for {
+		c.mu.Lock()
+		if len(c.queue) > 0 {
+			msg := c.queue[0]
+			c.queue = c.queue[1:]
+			c.mu.Unlock()
+			return msg.messageType, msg.data, nil
+		}
+		readErr := c.readErr
+		c.mu.Unlock()
+		if readErr != nil {
+			return 0, nil, readErr
+		}
+		<-c.notifyCh
+	}
+}
+
+// WriteMessage writes a message to the connection.
+//   - TextMessage and BinaryMessage are sent as data messages.
+//   - CloseMessage closes the connection with the code and the reason in data. See FormatCloseMessage.
+//   - PingMessage and PongMessage are not supported and ErrControlMessageNotSupported is returned.
+func (c *Conn) WriteMessage(messageType MessageType, data []byte) error {
+	switch messageType {
+	case TextMessage:
+		return c.send(js.ValueOf(string(data)))
+	case BinaryMessage:
+		ua := jsutil.NewUint8Array(len(data))
+		js.CopyBytesToJS(ua, data)
+		return c.send(ua)
+	case CloseMessage:
+		code, text := parseCloseMessage(data)
+		return c.CloseWithCode(code, text)
+	case PingMessage, PongMessage:
+		return ErrControlMessageNotSupported
+	}
+	return errors.New("websocket: unknown message type: " + messageType.String())
+}
+
+func (c *Conn) send(v js.Value) error {
+	c.mu.Lock()
+	closeSent := c.closeSent
+	c.mu.Unlock()
+	if closeSent {
+		return ErrCloseSent
+	}
+	_, err := jsutil.TryCatchCall(c.ws, "send", v)
+	return err
+}
+
+// Close closes the connection with CloseNormalClosure.
+func (c *Conn) Close() error {
+	return c.CloseWithCode(CloseNormalClosure, "")
+}
+
+// CloseWithCode closes the connection with the given close code and reason.
+func (c *Conn) CloseWithCode(code int, text string) error {
+	c.mu.Lock()
+	if c.closeSent {
+		c.mu.Unlock()
+		return ErrCloseSent
+	}
+	c.closeSent = true
+	c.mu.Unlock()
+	return c.close(code, text)
+}
+
+func (c *Conn) close(code int, text string) error {
+	if code == CloseNoStatusReceived {
+		_, err := jsutil.TryCatchCall(c.ws, "close")
+		return err
+	}
+	_, err := jsutil.TryCatchCall(c.ws, "close", code, text)
+	return err
+}
@@ -0,0 +1,162 @@
+package websocket
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"syscall/js"
+	"testing"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+func TestMain(m *testing.M) {
+	// tryCatch is provided by worker.mjs on the runtime.
+	js.Global().Set("tryCatch", js.Global().Get("Function").New("fn", `
+		try {
+			return { result: fn() };
+		} catch (e) {
+			return { error: e };
+		}
+	`))
+	os.Exit(m.Run())
+}
+
+type fakeWebSocket struct {
+	obj    js.Value
+	sent   []js.Value
+	closed []int
+}
+
+func newFakeWebSocket() *fakeWebSocket {
+	ws := &fakeWebSocket{
+		obj: js.Global().Get("EventTarget").New(),
+	}
+	ws.obj.Set("send", js.FuncOf(func(_ js.Value, args []js.Value) any {
+		ws.sent = append(ws.sent, args[0])
+		return js.Undefined()
+	}))
+	ws.obj.Set("close", js.FuncOf(func(_ js.Value, args []js.Value) any {
+		code := CloseNoStatusReceived
+		if len(args) > 0 {
+			code = args[0].Int()
+		}
+		ws.closed = append(ws.closed, code)
+		return js.Undefined()
+	}))
+	return ws
+}
+
+func (ws *fakeWebSocket) dispatchMessage(data any) {
+	init := jsutil.NewObject()
+	init.Set("data", data)
+	ws.obj.Call("dispatchEvent", js.Global().Get("MessageEvent").New("message", init))
+}
+
+func (ws *fakeWebSocket) dispatchClose(code int, reason string) {
+	event := js.Global().Get("Event").New("close")
+	event.Set("code", code)
+	event.Set("reason", reason)
+	ws.obj.Call("dispatchEvent", event)
+}
+
+func TestConn_ReadMessage(t *testing.T) {
+	ws := newFakeWebSocket()
+	c := newConn(ws.obj)
+
+	ws.dispatchMessage("hello")
+	ua := jsutil.NewUint8Array(3)
+	js.CopyBytesToJS(ua, []byte{1, 2, 3})
+	ws.dispatchMessage(ua.Get("buffer"))
+	ws.dispatchClose(CloseGoingAway, "bye")
+
+	typ, data, err := c.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	if typ != TextMessage || string(data) != "hello" {
+		t.Errorf("ReadMessage() = %v, %q, want %v, %q", typ, data, TextMessage, "hello")
+	}
+
+	typ, data, err = c.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	if typ != BinaryMessage || !bytes.Equal(data, []byte{1, 2, 3}) {
+		t.Errorf("ReadMessage() = %v, %v, want %v, %v", typ, data, BinaryMessage, []byte{1, 2, 3})
+	}
+
+	_, _, err = c.ReadMessage()
+	var closeErr *CloseError
+	if !errors.As(err, &closeErr) {
+		t.Fatalf("ReadMessage() error = %v, want *CloseError", err)
+	}
+	if closeErr.Code != CloseGoingAway || closeErr.Text != "bye" {
+		t.Errorf("CloseError = %+v, want code %d and text %q", closeErr, CloseGoingAway, "bye")
+	}
+	if len(ws.closed) != 1 || ws.closed[0] != CloseGoingAway {
+		t.Errorf("close must be echoed once with code %d, got %v", CloseGoingAway, ws.closed)
+	}
+}
+
+func TestConn_WriteMessage(t *testing.T) {
+	ws := newFakeWebSocket()
+	c := newConn(ws.obj)
+
+	if err := c.WriteMessage(TextMessage, []byte("hello")); err != nil {
+		t.Fatalf("WriteMessage() error = %v", err)
+	}
+	if err := c.WriteMessage(BinaryMessage, []byte{1, 2, 3}); err != nil {
+		t.Fatalf("WriteMessage() error = %v", err)
+	}
+	if err := c.WriteMessage(PingMessage, nil); !errors.Is(err, ErrControlMessageNotSupported) {
+		t.Errorf("WriteMessage() error = %v, want %v", err, ErrControlMessageNotSupported)
+	}
+	if err := c.WriteMessage(CloseMessage, FormatCloseMessage(CloseNormalClosure, "done")); err != nil {
+		t.Fatalf("WriteMessage() error = %v", err)
+	}
+	if err := c.WriteMessage(TextMessage, []byte("after close")); !errors.Is(err, ErrCloseSent) {
+		t.Errorf("WriteMessage() error = %v, want %v", err, ErrCloseSent)
+	}
+
+	if len(ws.sent) != 2 {
+		t.Fatalf("sent %d messages, want 2", len(ws.sent))
+	}
+	if got := ws.sent[0].String(); got != "hello" {
+		t.Errorf("sent text = %q, want %q", got, "hello")
+	}
+	got := make([]byte, ws.sent[1].Length())
+	js.CopyBytesToGo(got, ws.sent[1])
+	if !bytes.Equal(got, []byte{1, 2, 3}) {
+		t.Errorf("sent binary = %v, want %v", got, []byte{1, 2, 3})
+	}
+	if len(ws.closed) != 1 || ws.closed[0] != CloseNormalClosure {
+		t.Errorf("closed = %v, want [%d]", ws.closed, CloseNormalClosure)
+	}
+}
+
+func TestConn_WriteMessage_Throw(t *testing.T) {
+	ws := newFakeWebSocket()
+	throw := js.Global().Get("Function").New(`throw new TypeError("WebSocket is closed");`)
+	ws.obj.Set("send", throw)
+	ws.obj.Set("close", throw)
+	c := newConn(ws.obj)
+
+	if err := c.WriteMessage(TextMessage, []byte("hello")); err == nil {
+		t.Error("WriteMessage() error = nil, want error thrown by send")
+	}
+	if err := c.Close(); err == nil {
+		t.Error("Close() error = nil, want error thrown by close")
+	}
+}
+
+func TestFormatCloseMessage(t *testing.T) {
+	code, text := parseCloseMessage(FormatCloseMessage(CloseGoingAway, "bye"))
+	if code != CloseGoingAway || text != "bye" {
+		t.Errorf("parseCloseMessage() = %d, %q, want %d, %q", code, text, CloseGoingAway, "bye")
+	}
+	code, text = parseCloseMessage(FormatCloseMessage(CloseNoStatusReceived, ""))
+	if code != CloseNormalClosure || text != "" {
+		t.Errorf("parseCloseMessage() = %d, %q, want %d, %q", code, text, CloseNormalClosure, "")
+	}
+}
@@ -0,0 +1,120 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/syumai/workers/cloudflare/fetch"
+	"github.com/syumai/workers/internal/jshttp"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// ErrBadHandshake is returned by Dial when the server doesn't accept WebSocket upgrade.
+var ErrBadHandshake = errors.New("websocket: bad handshake")
+
+// DialOptions represents the options of Dial.
+type DialOptions struct {
+	// Header is added to the upgrade request.
+	Header http.Header
+	// Client is used to send the upgrade request.
+	// To connect to other workers through service bindings, use fetch.NewClient(fetch.WithBinding(...)).
+	// If nil, fetch.NewClient() is used.
+	Client *fetch.Client
+}
+
+// Dial opens a WebSocket connection to the given URL through fetch with `Upgrade: websocket` header.
+//   - ws and wss schemes are converted into http and https because fetch only supports them.
+//   - ctx is used only for the handshake. The returned connection is not closed when ctx is done.
+//   - If the server doesn't respond with 101 Switching Protocols, ErrBadHandshake is returned with the response.
+//   - If the WebSocket can't be accepted, the error is returned with the response.
+//   - https://developers.cloudflare.com/workers/examples/websockets/#write-a-websocket-client
+func Dial(ctx context.Context, rawURL string, opts *DialOptions) (*Conn, *http.Response, error) {
+	if opts == nil {
+		opts = &DialOptions{}
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	case "http", "https":
+	default:
+		return nil, nil, fmt.Errorf("websocket: unsupported URL scheme: %s", u.Scheme)
+	}
+	client := opts.Client
+	if client == nil {
+		client = fetch.NewClient()
+	}
+
+	// the request must not be aborted after the handshake, so its context is not cancelled with ctx.
+	req, err := fetch.NewRequest(context.WithoutCancel(ctx), http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	for k, vs := range opts.Header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	req.Header.Set("Upgrade", "websocket")
+
+	type result struct {
+		resp *http.Response
+		err  error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		resp, err := client.Do(req, nil)
+		resultCh <- result{resp: resp, err: err}
+	}()
+
+	var r result
+	select {
+	case r = <-resultCh:
+	case <-ctx.Done():
+		go func() {
+			// close the connection which is established after ctx is done.
+			if r := <-resultCh; r.err == nil {
+				if conn, err := newClientConn(r.resp); err == nil {
+					conn.Close()
+				}
+			}
+		}()
+		return nil, nil, ctx.Err()
+	}
+	if r.err != nil {
+		return nil, nil, r.err
+	}
+	conn, err := newClientConn(r.resp)
+	if err != nil {
+		return nil, r.resp, err
+	}
+	return conn, r.resp, nil
+}
+
+// newClientConn accepts the WebSocket carried by the response and returns its connection.
+//   - If the response doesn't carry a WebSocket, ErrBadHandshake is returned.
+//   - If the WebSocket can't be accepted, the error thrown by accept is returned.
+func newClientConn(resp *http.Response) (*Conn, error) {
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return nil, ErrBadHandshake
+	}
+	getter, ok := resp.Body.(jshttp.WebSocketGetter)
+	if !ok {
+		return nil, ErrBadHandshake
+	}
+	ws := getter.GetWebSocket()
+	conn := newConn(ws)
+	if _, err := jsutil.TryCatchCall(ws, "accept"); err != nil {
+		conn.releaseListeners()
+		return nil, err
+	}
+	return conn, nil
+}
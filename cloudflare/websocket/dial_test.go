@@ -0,0 +1,94 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"syscall/js"
+	"testing"
+
+	"github.com/syumai/workers/cloudflare/fetch"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// newFakeFetcher returns an object which has fetch method responding with the given status and WebSocket.
+func newFakeFetcher(t *testing.T, status int, ws js.Value) js.Value {
+	fetcher := jsutil.NewObject()
+	fetcher.Set("fetch", js.FuncOf(func(_ js.Value, args []js.Value) any {
+		req := args[0]
+		if got := req.Get("url").String(); got != "https://example.com/ws" {
+			t.Errorf("url = %q, want %q", got, "https://example.com/ws")
+		}
+		if got := req.Get("headers").Call("get", "Upgrade").String(); got != "websocket" {
+			t.Errorf("Upgrade header = %q, want %q", got, "websocket")
+		}
+		res := jsutil.NewObject()
+		res.Set("status", status)
+		res.Set("statusText", http.StatusText(status))
+		res.Set("headers", jsutil.HeadersClass.New())
+		res.Set("body", jsutil.Null)
+		res.Set("webSocket", ws)
+		return jsutil.PromiseClass.Call("resolve", res)
+	}))
+	return fetcher
+}
+
+func TestDial(t *testing.T) {
+	ws := newFakeWebSocket()
+	accepted := false
+	ws.obj.Set("accept", js.FuncOf(func(js.Value, []js.Value) any {
+		accepted = true
+		return js.Undefined()
+	}))
+	client := fetch.NewClient(fetch.WithBinding(newFakeFetcher(t, http.StatusSwitchingProtocols, ws.obj)))
+
+	conn, resp, err := Dial(context.Background(), "wss://example.com/ws", &DialOptions{Client: client})
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("StatusCode = %d, want %d", resp.StatusCode, http.StatusSwitchingProtocols)
+	}
+	if !accepted {
+		t.Error("WebSocket must be accepted")
+	}
+
+	ws.dispatchMessage("hello")
+	typ, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	if typ != TextMessage || string(data) != "hello" {
+		t.Errorf("ReadMessage() = %v, %q, want %v, %q", typ, data, TextMessage, "hello")
+	}
+}
+
+func TestDial_BadHandshake(t *testing.T) {
+	client := fetch.NewClient(fetch.WithBinding(newFakeFetcher(t, http.StatusOK, jsutil.Null)))
+
+	_, resp, err := Dial(context.Background(), "https://example.com/ws", &DialOptions{Client: client})
+	if !errors.Is(err, ErrBadHandshake) {
+		t.Fatalf("Dial() error = %v, want %v", err, ErrBadHandshake)
+	}
+	if resp == nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("response must be returned with status %d, got %v", http.StatusOK, resp)
+	}
+}
+
+func TestDial_AcceptError(t *testing.T) {
+	ws := newFakeWebSocket()
+	ws.obj.Set("accept", js.Global().Get("Function").New(`throw new Error("accept failed");`))
+	client := fetch.NewClient(fetch.WithBinding(newFakeFetcher(t, http.StatusSwitchingProtocols, ws.obj)))
+
+	conn, resp, err := Dial(context.Background(), "wss://example.com/ws", &DialOptions{Client: client})
+	if err == nil || !strings.Contains(err.Error(), "accept failed") {
+		t.Fatalf("Dial() error = %v, want accept failed", err)
+	}
+	if conn != nil {
+		t.Error("connection must not be returned")
+	}
+	if resp == nil || resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("response must be returned with status %d, got %v", http.StatusSwitchingProtocols, resp)
+	}
+}
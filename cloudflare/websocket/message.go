@@ -0,0 +1,91 @@
+package websocket
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// MessageType represents the type of a WebSocket message.
+// The values are the same as the opcodes defined in RFC 6455.
+type MessageType int
+
+const (
+	// TextMessage denotes a text data message. The text message payload is interpreted as UTF-8 encoded text data.
+	TextMessage MessageType = 1
+	// BinaryMessage denotes a binary data message.
+	BinaryMessage MessageType = 2
+	// CloseMessage denotes a close control message.
+	// The payload may contain a close code and a reason. Use FormatCloseMessage to format it.
+	CloseMessage MessageType = 8
+	// PingMessage denotes a ping control message.
+	// Cloudflare Workers runtime responds to pings automatically, so this can't be sent from Go.
+	PingMessage MessageType = 9
+	// PongMessage denotes a pong control message.
+	// Cloudflare Workers runtime responds to pings automatically, so this can't be sent from Go.
+	PongMessage MessageType = 10
+)
+
+func (t MessageType) String() string {
+	switch t {
+	case TextMessage:
+		return "text"
+	case BinaryMessage:
+		return "binary"
+	case CloseMessage:
+		return "close"
+	case PingMessage:
+		return "ping"
+	case PongMessage:
+		return "pong"
+	}
+	return fmt.Sprintf("MessageType(%d)", int(t))
+}
+
+// Close codes defined in RFC 6455, section 11.7.
+const (
+	CloseNormalClosure           = 1000
+	CloseGoingAway               = 1001
+	CloseProtocolError           = 1002
+	CloseUnsupportedData         = 1003
+	CloseNoStatusReceived        = 1005
+	CloseAbnormalClosure         = 1006
+	CloseInvalidFramePayloadData = 1007
+	ClosePolicyViolation         = 1008
+	CloseMessageTooBig           = 1009
+	CloseMandatoryExtension      = 1010
+	CloseInternalServerErr       = 1011
+)
+
+// CloseError represents a close message received from the peer.
+type CloseError struct {
+	// Code is the close code sent by the peer.
+	Code int
+	// Text is the close reason sent by the peer.
+	Text string
+}
+
+func (e *CloseError) Error() string {
+	if e.Text == "" {
+		return fmt.Sprintf("websocket: close %d", e.Code)
+	}
+	return fmt.Sprintf("websocket: close %d: %s", e.Code, e.Text)
+}
+
+// FormatCloseMessage formats a close code and a reason into the payload of a CloseMessage.
+func FormatCloseMessage(code int, text string) []byte {
+	if code == CloseNoStatusReceived {
+		return []byte{}
+	}
+	buf := make([]byte, 2+len(text))
+	binary.BigEndian.PutUint16(buf, uint16(code))
+	copy(buf[2:], text)
+	return buf
+}
+
+// parseCloseMessage parses the payload of a CloseMessage into a close code and a reason.
+func parseCloseMessage(data []byte) (code int, text string) {
+	if len(data) < 2 {
+		return CloseNormalClosure, ""
+	}
+	return int(binary.BigEndian.Uint16(data)), string(data[2:])
+}
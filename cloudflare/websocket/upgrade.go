@@ -0,0 +1,59 @@
+package websocket
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"syscall/js"
+
+	"github.com/syumai/workers/internal/jshttp"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+var (
+	// ErrNotWebSocketRequest is returned by Upgrade when the request doesn't ask for WebSocket upgrade.
+	ErrNotWebSocketRequest = errors.New("websocket: request is not a WebSocket upgrade request")
+	// ErrUpgradeNotSupported is returned by Upgrade when the response can't carry WebSocket.
+	// Upgrade works only with the http.ResponseWriter given by workers.Serve on Cloudflare Workers.
+	ErrUpgradeNotSupported = errors.New("websocket: upgrade is not supported by this http.ResponseWriter")
+)
+
+// IsWebSocketUpgrade reports whether the request asks for WebSocket upgrade.
+func IsWebSocketUpgrade(r *http.Request) bool {
+	for _, v := range r.Header.Values("Upgrade") {
+		for _, token := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), "websocket") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Upgrade upgrades the HTTP request to WebSocket and returns the server side connection.
+//   - A WebSocketPair is created, and its client side is set to the 101 Switching Protocols response.
+//   - After Upgrade succeeds, the response body can't be written.
+//   - If the request is not a WebSocket upgrade request, 426 Upgrade Required is responded and ErrNotWebSocketRequest is returned.
+//   - If the WebSocket can't be accepted, 500 Internal Server Error is responded and the error is returned.
+//   - https://developers.cloudflare.com/workers/runtime-apis/websockets/
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if !IsWebSocketUpgrade(r) {
+		w.Header().Set("Upgrade", "websocket")
+		http.Error(w, http.StatusText(http.StatusUpgradeRequired), http.StatusUpgradeRequired)
+		return nil, ErrNotWebSocketRequest
+	}
+	var conn *Conn
+	ok, err := jshttp.UpgradeToWebSocket(w, func(server js.Value) error {
+		conn = newConn(server)
+		_, err := jsutil.TryCatchCall(server, "accept")
+		return err
+	})
+	if !ok {
+		return nil, ErrUpgradeNotSupported
+	}
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil, err
+	}
+	return conn, nil
+}
@@ -0,0 +1,63 @@
+import { createRuntimeContext, getSharedBinding } from "./instance.mjs";
+
+// GoDurableObject is the base class of the Durable Object classes implemented in Go.
+// Durable Objects always run on the shared Go instance since they hold their state in memory.
+export class GoDurableObject {
+  #className;
+  #binding;
+  #object;
+
+  constructor(className, state, env) {
+    this.#className = className;
+    this.state = state;
+    this.env = env;
+  }
+
+  #runtimeContext() {
+    return createRuntimeContext({ env: this.env, ctx: this.state });
+  }
+
+  // getObject returns the Go side object of this Durable Object.
+  // The object is created again if the shared Go instance has been rebooted,
+  // or if the previous creation has failed.
+  async #getObject() {
+    const binding = await getSharedBinding(this.env, this.state);
+    if (this.#binding !== binding) {
+      this.#binding = binding;
+      const object = binding.newDurableObject(this.#className, this.state, this.#runtimeContext());
+      this.#object = object;
+      Promise.resolve(object).catch(() => {
+        if (this.#object === object) {
+          this.#binding = undefined;
+          this.#object = undefined;
+        }
+      });
+    }
+    return this.#object;
+  }
+
+  async fetch(req) {
+    const object = await this.#getObject();
+    return object.fetch(req, this.#runtimeContext());
+  }
+
+  async alarm(alarmInfo) {
+    const object = await this.#getObject();
+    return object.alarm(alarmInfo, this.#runtimeContext());
+  }
+
+  async webSocketMessage(ws, message) {
+    const object = await this.#getObject();
+    return object.webSocketMessage(ws, message, this.#runtimeContext());
+  }
+
+  async webSocketClose(ws, code, reason, wasClean) {
+    const object = await this.#getObject();
+    return object.webSocketClose(ws, code, reason, wasClean, this.#runtimeContext());
+  }
+
+  async webSocketError(ws, error) {
+    const object = await this.#getObject();
+    return object.webSocketError(ws, error, this.#runtimeContext());
+  }
+}
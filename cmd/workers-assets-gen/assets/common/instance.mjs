@@ -0,0 +1,88 @@
+import "./wasm_exec.js";
+import { createRuntimeContext, loadModule } from "./runtime.mjs";
+import { reuseInstance } from "./instance_mode.mjs";
+
+let mod;
+let sharedBindingPromise;
+
+globalThis.tryCatch = (fn) => {
+  try {
+    return {
+      result: fn(),
+    };
+  } catch (e) {
+    return {
+      error: e,
+    };
+  }
+};
+
+// callOrThrow calls fn which returns { result, error } like tryCatch, and throws the error if any.
+// This allows Go functions to throw errors on the JS side.
+globalThis.callOrThrow = (fn, ...args) => {
+  const { result, error } = fn(...args);
+  if (error !== undefined) {
+    throw error;
+  }
+  return result;
+};
+
+async function run(ctx, onExit) {
+  if (mod === undefined) {
+    mod = await loadModule();
+  }
+  const go = new Go();
+
+  let ready;
+  const readyPromise = new Promise((resolve) => {
+    ready = resolve;
+  });
+  const instance = new WebAssembly.Instance(mod, {
+    ...go.importObject,
+    workers: {
+      ready: () => {
+        ready();
+      },
+    },
+  });
+  const exitPromise = go.run(instance, ctx);
+  if (onExit !== undefined) {
+    exitPromise.finally(onExit);
+  }
+  await readyPromise;
+}
+
+// getSharedBinding returns the binding of a Go instance which stays resident in this isolate.
+// The instance is booted on the first call, and booted again if it has exited.
+export function getSharedBinding(env, ctx) {
+  if (sharedBindingPromise === undefined) {
+    const binding = {};
+    const runtimeCtx = createRuntimeContext({ env, ctx, binding });
+    runtimeCtx.reuseInstance = true;
+    const promise = run(runtimeCtx, () => {
+      if (sharedBindingPromise === promise) {
+        sharedBindingPromise = undefined;
+      }
+    }).then(() => binding);
+    promise.catch(() => {
+      if (sharedBindingPromise === promise) {
+        sharedBindingPromise = undefined;
+      }
+    });
+    sharedBindingPromise = promise;
+  }
+  return sharedBindingPromise;
+}
+
+// getBinding returns the binding of a Go instance to handle an event.
+// A new instance is booted for each event unless the instance is configured to be reused.
+export async function getBinding(env, ctx) {
+  if (reuseInstance) {
+    return getSharedBinding(env, ctx);
+  }
+  const binding = {};
+  await run(createRuntimeContext({ env, ctx, binding }));
+  return binding;
+}
+
+export { createRuntimeContext };
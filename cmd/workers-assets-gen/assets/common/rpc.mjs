@@ -0,0 +1,7 @@
+import { createRuntimeContext, getBinding } from "./instance.mjs";
+
+// callRPC calls the RPC method exported from Go by workers.ExportRPC.
+export async function callRPC(env, ctx, entrypoint, method, args) {
+  const binding = await getBinding(env, ctx);
+  return binding.callRPC(entrypoint, method, args, createRuntimeContext({ env, ctx }));
+}
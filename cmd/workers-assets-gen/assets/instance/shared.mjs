@@ -0,0 +1 @@
+export const reuseInstance = true;
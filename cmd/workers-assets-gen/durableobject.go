@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// jsIdentifierPattern matches identifiers which can be used as JS class names.
+var jsIdentifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// parseDurableObjects parses a comma-separated list of Durable Object class names.
+func parseDurableObjects(s string) ([]string, error) {
+	var classNames []string
+	seen := map[string]bool{}
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !jsIdentifierPattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid durable object class name: %q", name)
+		}
+		if name == "default" {
+			return nil, fmt.Errorf("reserved durable object class name: %q", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate durable object class name: %q", name)
+		}
+		seen[name] = true
+		classNames = append(classNames, name)
+	}
+	return classNames, nil
+}
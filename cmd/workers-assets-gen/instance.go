@@ -0,0 +1,22 @@
+package main
+
+type InstanceMode string
+
+const (
+	// InstanceModePerEvent boots a new Go instance for each event.
+	InstanceModePerEvent InstanceMode = "per-event"
+	// InstanceModeShared keeps one Go instance resident per isolate and reuses it across events.
+	InstanceModeShared InstanceMode = "shared"
+)
+
+func (m InstanceMode) IsValid() bool {
+	switch m {
+	case InstanceModePerEvent, InstanceModeShared:
+		return true
+	}
+	return false
+}
+
+func (m InstanceMode) AssetFileName() string {
+	return string(m) + ".mjs"
+}
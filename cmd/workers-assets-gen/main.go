@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"path"
+	"text/template"
 )
 
 //go:embed assets
@@ -17,17 +18,29 @@ const (
 	assetDirPath        = "assets"
 	commonDirPath       = "assets/common"
 	runtimeDirPath      = "assets/runtime"
+	instanceDirPath     = "assets/instance"
+	templateDirPath     = "assets/templates"
 	defaultBuildDirPath = "build"
 )
 
 func main() {
 	var (
-		mode         string
-		runtime      string
-		buildDirPath string
+		mode           string
+		runtime        string
+		instanceMode   string
+		durableObjects string
+		rpc            string
+		email          bool
+		tail           bool
+		buildDirPath   string
 	)
 	flag.StringVar(&mode, "mode", string(ModeTinygo), `build mode: tinygo or go`)
 	flag.StringVar(&runtime, "runtime", string(RuntimeCloudflare), `runtime: cloudflare`)
+	flag.StringVar(&instanceMode, "instance", string(InstanceModePerEvent), `instance mode: per-event or shared`)
+	flag.StringVar(&durableObjects, "durable-objects", "", `comma-separated Durable Object class names implemented in Go`)
+	flag.StringVar(&rpc, "rpc", "", `comma-separated RPC methods implemented in Go as Entrypoint.method`)
+	flag.BoolVar(&email, "email", false, `export the email handler implemented in Go`)
+	flag.BoolVar(&tail, "tail", false, `export the tail handler implemented in Go`)
 	flag.StringVar(&buildDirPath, "o", defaultBuildDirPath, `output dir path: defaults to "build"`)
 	flag.Parse()
 	if !Mode(mode).IsValid() {
@@ -40,13 +53,42 @@ func main() {
 		os.Exit(1)
 		return
 	}
-	if err := runMain(Mode(mode), Runtime(runtime), buildDirPath); err != nil {
+	if !InstanceMode(instanceMode).IsValid() {
+		flag.PrintDefaults()
+		os.Exit(1)
+		return
+	}
+	classNames, err := parseDurableObjects(durableObjects)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "err: %v", err)
+		os.Exit(1)
+	}
+	rpcEntrypoints, err := parseRPCEntrypoints(rpc)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "err: %v", err)
+		os.Exit(1)
+	}
+	for _, ep := range rpcEntrypoints {
+		for _, className := range classNames {
+			if ep.Name == className {
+				fmt.Fprintf(os.Stderr, "err: %s is used as both durable object and rpc entrypoint", ep.Name)
+				os.Exit(1)
+			}
+		}
+	}
+	worker := &Worker{
+		DurableObjects: classNames,
+		RPCEntrypoints: rpcEntrypoints,
+		Email:          email,
+		Tail:           tail,
+	}
+	if err := runMain(Mode(mode), Runtime(runtime), InstanceMode(instanceMode), worker, buildDirPath); err != nil {
 		fmt.Fprintf(os.Stderr, "err: %v", err)
 		os.Exit(1)
 	}
 }
 
-func runMain(mode Mode, runtime Runtime, buildDirPath string) error {
+func runMain(mode Mode, runtime Runtime, instanceMode InstanceMode, worker *Worker, buildDirPath string) error {
 	if err := os.RemoveAll(buildDirPath); err != nil {
 		return err
 	}
@@ -59,9 +101,15 @@ func runMain(mode Mode, runtime Runtime, buildDirPath string) error {
 	if err := copyRuntimeAssets(runtime, buildDirPath); err != nil {
 		return err
 	}
+	if err := copyInstanceModeAssets(instanceMode, buildDirPath); err != nil {
+		return err
+	}
 	if err := copyCommonAssets(buildDirPath); err != nil {
 		return err
 	}
+	if err := writeWorkerJS(worker, buildDirPath); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -92,6 +140,15 @@ func copyRuntimeAssets(runtime Runtime, buildDirPath string) error {
 	return nil
 }
 
+func copyInstanceModeAssets(instanceMode InstanceMode, buildDirPath string) error {
+	destPath := path.Join(buildDirPath, "instance_mode.mjs")
+	originPath := path.Join(instanceDirPath, instanceMode.AssetFileName())
+	if err := copyFile(destPath, originPath); err != nil {
+		return err
+	}
+	return nil
+}
+
 func copyCommonAssets(buildDirPath string) error {
 	entries, err := assets.ReadDir(commonDirPath)
 	if err != nil {
@@ -107,6 +164,30 @@ func copyCommonAssets(buildDirPath string) error {
 	return nil
 }
 
+// Worker represents the handlers and classes exported from worker.mjs in addition to the default handlers.
+type Worker struct {
+	DurableObjects []string
+	RPCEntrypoints []*RPCEntrypoint
+	// Email reports whether the email handler is exported.
+	Email bool
+	// Tail reports whether the tail handler is exported.
+	Tail bool
+}
+
+// writeWorkerJS writes worker.mjs which exports the handlers, the Durable Object classes and the RPC entrypoints.
+func writeWorkerJS(worker *Worker, buildDirPath string) error {
+	tmpl, err := template.ParseFS(assets, path.Join(templateDirPath, "worker.mjs.tmpl"))
+	if err != nil {
+		return err
+	}
+	dest, err := os.Create(path.Join(buildDirPath, "worker.mjs"))
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+	return tmpl.Execute(dest, worker)
+}
+
 func copyFile(destPath, originPath string) error {
 	f, err := assets.ReadFile(originPath)
 	if err != nil {
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RPCEntrypoint represents a WorkerEntrypoint class whose methods are implemented in Go.
+type RPCEntrypoint struct {
+	Name    string
+	Methods []string
+}
+
+// parseRPCEntrypoints parses a comma-separated list of `Entrypoint.method`.
+// Entrypoints and methods are kept in the given order.
+func parseRPCEntrypoints(s string) ([]*RPCEntrypoint, error) {
+	var entrypoints []*RPCEntrypoint
+	byName := map[string]*RPCEntrypoint{}
+	seen := map[string]bool{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, method, ok := strings.Cut(item, ".")
+		if !ok || !jsIdentifierPattern.MatchString(name) || !jsIdentifierPattern.MatchString(method) {
+			return nil, fmt.Errorf("invalid rpc method: %q (want Entrypoint.method)", item)
+		}
+		if name == "default" {
+			return nil, fmt.Errorf("reserved rpc entrypoint name: %q", name)
+		}
+		if method == "constructor" || method == "fetch" {
+			return nil, fmt.Errorf("reserved rpc method name: %q", item)
+		}
+		if seen[item] {
+			return nil, fmt.Errorf("duplicate rpc method: %q", item)
+		}
+		seen[item] = true
+		ep, ok := byName[name]
+		if !ok {
+			ep = &RPCEntrypoint{Name: name}
+			byName[name] = ep
+			entrypoints = append(entrypoints, ep)
+		}
+		ep.Methods = append(ep.Methods, method)
+	}
+	return entrypoints, nil
+}
@@ -17,7 +17,10 @@ type Context struct {
 	reqFunc func() *http.Request
 }
 
-func newContext(ctxObj js.Value) *Context {
+// newContext creates a Context from the runtime context object of the event.
+// The runtime context object holds the Hono context as `ctx`.
+func newContext(runtimeObj js.Value) *Context {
+	ctxObj := runtimeObj.Get("ctx")
 	return &Context{
 		ctxObj: ctxObj,
 		reqFunc: sync.OnceValue(func() *http.Request {
@@ -26,7 +29,7 @@ func newContext(ctxObj js.Value) *Context {
 			if err != nil {
 				panic(err)
 			}
-			ctx := runtimecontext.New(context.Background(), reqObj)
+			ctx := runtimecontext.New(context.Background(), reqObj, runtimeObj)
 			req = req.WithContext(ctx)
 			return req
 		}),
@@ -2,9 +2,11 @@ package hono
 
 import (
 	"fmt"
+	"runtime/debug"
 	"syscall/js"
 
 	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/recovery"
 )
 
 type Middleware func(c *Context, next func())
@@ -32,18 +34,24 @@ func ChainMiddlewares(middlewares ...Middleware) Middleware {
 
 func init() {
 	runHonoMiddlewareCallback := js.FuncOf(func(_ js.Value, args []js.Value) any {
-		if len(args) > 1 {
-			panic(fmt.Errorf("too many args given to handleRequest: %d", len(args)))
+		if len(args) != 1 && len(args) != 2 {
+			panic(fmt.Errorf("invalid number of arguments given to runHonoMiddleware: %d", len(args)))
 		}
 		nextFnObj := args[0]
+		runtimeObj := jsutil.RuntimeContext
+		if len(args) > 1 {
+			runtimeObj = args[1]
+		}
 		var cb js.Func
 		cb = js.FuncOf(func(_ js.Value, pArgs []js.Value) any {
 			defer cb.Release()
 			resolve := pArgs[0]
+			reject := pArgs[1]
 			go func() {
-				err := runHonoMiddleware(nextFnObj)
+				err := runHonoMiddleware(nextFnObj, runtimeObj)
 				if err != nil {
-					panic(err)
+					reject.Invoke(jsutil.Error(err.Error()))
+					return
 				}
 				resolve.Invoke(js.Undefined())
 			}()
@@ -54,11 +62,19 @@ func init() {
 	jsutil.Binding.Set("runHonoMiddleware", runHonoMiddlewareCallback)
 }
 
-func runHonoMiddleware(nextFnObj js.Value) error {
+// runHonoMiddleware runs the middleware.
+// A panic in the middleware is recovered, reported and returned as an error.
+func runHonoMiddleware(nextFnObj, runtimeObj js.Value) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			recovery.Report(nil, v, debug.Stack())
+			err = recovery.Error(v)
+		}
+	}()
 	if middleware == nil {
 		return fmt.Errorf("ServeMiddleware must be called before runHonoMiddleware.")
 	}
-	c := newContext(jsutil.RuntimeContext.Get("ctx"))
+	c := newContext(runtimeObj)
 	next := func() {
 		jsutil.AwaitPromise(nextFnObj.Invoke())
 	}
@@ -1,6 +1,16 @@
 package hono
 
-import "testing"
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"syscall/js"
+	"testing"
+
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/recovery"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
 
 func TestChainMiddlewares(t *testing.T) {
 	result := ""
@@ -30,3 +40,95 @@ func TestChainMiddlewares(t *testing.T) {
 		t.Errorf("result: got %q, want %q", result, want)
 	}
 }
+
+func newRuntimeObj(url, envName string) js.Value {
+	req := jsutil.NewObject()
+	req.Set("raw", jsutil.RequestClass.New(url))
+	honoCtx := jsutil.NewObject()
+	honoCtx.Set("req", req)
+	env := jsutil.NewObject()
+	env.Set("NAME", envName)
+	runtimeObj := jsutil.NewObject()
+	runtimeObj.Set("ctx", honoCtx)
+	runtimeObj.Set("env", env)
+	return runtimeObj
+}
+
+// TestRunHonoMiddleware_SharedInstance ensures the runtime context object of each event is used
+// when one instance handles multiple events.
+func TestRunHonoMiddleware_SharedInstance(t *testing.T) {
+	var gotURLs, gotEnvs []string
+	middleware = func(c *Context, next func()) {
+		req := c.Request()
+		gotURLs = append(gotURLs, req.URL.String())
+		runtimeObj, _ := runtimecontext.ExtractRuntimeObj(req.Context())
+		gotEnvs = append(gotEnvs, runtimeObj.Get("env").Get("NAME").String())
+		next()
+	}
+	t.Cleanup(func() { middleware = nil })
+
+	next := js.FuncOf(func(js.Value, []js.Value) any {
+		return jsutil.PromiseClass.Call("resolve")
+	})
+	defer next.Release()
+
+	for _, event := range []struct{ url, env string }{
+		{"https://example.com/first", "first"},
+		{"https://example.com/second", "second"},
+	} {
+		p := jsutil.Binding.Call("runHonoMiddleware", next, newRuntimeObj(event.url, event.env))
+		if _, err := jsutil.AwaitPromise(p); err != nil {
+			t.Fatalf("runHonoMiddleware failed: %v", err)
+		}
+	}
+
+	wantURLs := []string{"https://example.com/first", "https://example.com/second"}
+	wantEnvs := []string{"first", "second"}
+	if !reflect.DeepEqual(gotURLs, wantURLs) {
+		t.Errorf("request URLs = %v, want %v", gotURLs, wantURLs)
+	}
+	if !reflect.DeepEqual(gotEnvs, wantEnvs) {
+		t.Errorf("env names = %v, want %v", gotEnvs, wantEnvs)
+	}
+}
+
+func TestRunHonoMiddleware_Panic(t *testing.T) {
+	var reported []any
+	recovery.SetHook(func(_ *http.Request, v any, _ []byte) { reported = append(reported, v) })
+	t.Cleanup(func() { recovery.SetHook(nil) })
+	t.Cleanup(func() { middleware = nil })
+
+	next := js.FuncOf(func(js.Value, []js.Value) any {
+		return jsutil.PromiseClass.Call("resolve")
+	})
+	defer next.Release()
+
+	tests := map[string]Middleware{
+		"before next": func(c *Context, next func()) {
+			panic("boom")
+		},
+		"after writing the response": func(c *Context, next func()) {
+			next()
+			c.SetHeader("X-Partial", "true")
+			panic("boom")
+		},
+	}
+	for name, m := range tests {
+		t.Run(name, func(t *testing.T) {
+			reported = nil
+			middleware = m
+			runtimeObj := newRuntimeObj("https://example.com/", "panic")
+			runtimeObj.Get("ctx").Set("header", js.FuncOf(func(js.Value, []js.Value) any {
+				return js.Undefined()
+			}))
+			// the rejected middleware makes Hono respond with its error handler, which defaults to 500.
+			p := jsutil.Binding.Call("runHonoMiddleware", next, runtimeObj)
+			if _, err := jsutil.AwaitPromise(p); err == nil || !strings.Contains(err.Error(), "panic: boom") {
+				t.Errorf("runHonoMiddleware error = %v, want panic: boom", err)
+			}
+			if len(reported) != 1 || reported[0] != "boom" {
+				t.Errorf("reported = %v, want [boom]", reported)
+			}
+		})
+	}
+}
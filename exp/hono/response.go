@@ -11,7 +11,9 @@ import (
 
 func convertBodyToJS(body io.ReadCloser) js.Value {
 	if sr, ok := body.(jsutil.RawJSBodyGetter); ok {
-		return sr.GetRawJSBody()
+		if raw := sr.GetRawJSBody(); !raw.IsUndefined() {
+			return raw
+		}
 	}
 	return jsutil.ConvertReaderToReadableStream(body)
 }
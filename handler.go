@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+
+	"github.com/syumai/workers/internal/recovery"
 )
 
 // Server serves http.Handler as a normal HTTP server.
@@ -23,9 +25,22 @@ func Serve(handler http.Handler) {
 	addr := fmt.Sprintf(":%s", port)
 	fmt.Printf("listening on: http://localhost%s\n", addr)
 	fmt.Fprintln(os.Stderr, "warn: this server is currently running in non-JS mode. to enable JS-related features, please use the make command in the syumai/workers template.")
+	handler = recovery.Handler(handler)
+	if requestTimeout > 0 {
+		handler = timeoutHandler(handler)
+	}
 	http.ListenAndServe(addr, handler)
 }
 
+// timeoutHandler cancels the context of each request after the configured request timeout.
+func timeoutHandler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx, cancel := withRequestTimeout(req.Context())
+		defer cancel()
+		handler.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
 func ServeNonBlock(http.Handler) {
 	panic("ServeNonBlock is not supported in non-JS environments")
 }
@@ -23,17 +23,23 @@ func init() {
 	var handleRequestCallback js.Func
 	handleRequestCallback = js.FuncOf(func(this js.Value, args []js.Value) any {
 		reqObj := args[0]
+		// The runtime context object is given for each event by the JS side.
+		// If it is not given, the one bound to this instance is used.
+		runtimeObj := jsutil.RuntimeContext
+		if len(args) > 1 {
+			runtimeObj = args[1]
+		}
 		var cb js.Func
 		cb = js.FuncOf(func(_ js.Value, pArgs []js.Value) any {
 			defer cb.Release()
 			resolve := pArgs[0]
 			reject := pArgs[1]
 			go func() {
-				if len(args) > 1 {
+				if len(args) > 2 {
 					reject.Invoke(jsutil.Errorf("too many args given to handleRequest: %d", len(args)))
 					return
 				}
-				res, err := handleRequest(reqObj)
+				res, err := handleRequest(reqObj, runtimeObj)
 				if err != nil {
 					reject.Invoke(jsutil.Error(err.Error()))
 					return
@@ -51,37 +57,27 @@ type appCloser struct {
 	io.ReadCloser
 }
 
+// Close closes the response body.
+// If the instance is not reused across events, closing the body marks the handler as done.
 func (c *appCloser) Close() error {
-	defer close(doneCh)
+	if !jsutil.IsInstanceReused() {
+		defer close(doneCh)
+	}
 	return c.ReadCloser.Close()
 }
 
-// handleRequest accepts a Request object and returns Response object.
-func handleRequest(reqObj js.Value) (js.Value, error) {
+// handleRequest accepts a Request object and the runtime context object of the event, and returns Response object.
+func handleRequest(reqObj, runtimeObj js.Value) (js.Value, error) {
 	if httpHandler == nil {
 		return js.Value{}, fmt.Errorf("Serve must be called before handleRequest.")
 	}
-	req, err := jshttp.ToRequest(reqObj)
-	if err != nil {
-		return js.Value{}, err
-	}
-	ctx := runtimecontext.New(context.Background(), reqObj)
-	req = req.WithContext(ctx)
-	reader, writer := io.Pipe()
-	w := &jshttp.ResponseWriter{
-		HeaderValue: http.Header{},
-		StatusCode:  http.StatusOK,
-		Reader:      &appCloser{reader},
-		Writer:      writer,
-		ReadyCh:     make(chan struct{}),
-	}
-	go func() {
-		defer w.Ready()
-		defer writer.Close()
-		httpHandler.ServeHTTP(w, req)
-	}()
-	<-w.ReadyCh
-	return w.ToJSResponse(), nil
+	ctx := runtimecontext.New(context.Background(), reqObj, runtimeObj)
+	return jshttp.ServeRequest(ctx, httpHandler, reqObj, jshttp.ServeOptions{
+		Timeout: requestTimeout,
+		WrapBody: func(body io.ReadCloser) io.ReadCloser {
+			return &appCloser{body}
+		},
+	})
 }
 
 // Serve serves http.Handler on a JS runtime.
@@ -110,6 +106,7 @@ func Ready() {
 }
 
 // Done returns a channel which is closed when the handler is done.
+// If the instance is reused across events, this channel is never closed.
 func Done() <-chan struct{} {
 	return doneCh
 }
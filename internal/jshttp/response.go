@@ -23,21 +23,43 @@ func toResponse(res js.Value, body io.ReadCloser) (*http.Response, error) {
 	}, nil
 }
 
+// WebSocketGetter is implemented by the body of *http.Response which carries WebSocket.
+type WebSocketGetter interface {
+	GetWebSocket() js.Value
+}
+
+// webSocketBody is an empty body of *http.Response which carries WebSocket.
+type webSocketBody struct {
+	io.ReadCloser
+	webSocket js.Value
+}
+
+var _ WebSocketGetter = (*webSocketBody)(nil)
+
+func (b *webSocketBody) GetWebSocket() js.Value {
+	return b.webSocket
+}
+
 // ToResponse converts JavaScript sides Response to *http.Response.
 //   - Response: https://developer.mozilla.org/docs/Web/API/Response
+//   - If the Response has webSocket, the body of *http.Response is empty and implements WebSocketGetter.
 func ToResponse(res js.Value) (*http.Response, error) {
+	if ws := res.Get("webSocket"); !ws.IsUndefined() && !ws.IsNull() {
+		return toResponse(res, &webSocketBody{ReadCloser: http.NoBody, webSocket: ws})
+	}
 	body := jsutil.ConvertReadableStreamToReadCloser(res.Get("body"))
 	return toResponse(res, body)
 }
 
 // ToJSResponse converts *http.Response to JavaScript sides Response class object.
 func ToJSResponse(res *http.Response) js.Value {
-	return newJSResponse(res.StatusCode, res.Header, res.ContentLength, res.Body, nil)
+	return newJSResponse(res.StatusCode, res.Header, res.ContentLength, res.Body, nil, js.Undefined())
 }
 
 // newJSResponse creates JavaScript sides Response class object.
 //   - Response: https://developer.mozilla.org/docs/Web/API/Response
-func newJSResponse(statusCode int, headers http.Header, contentLength int64, body io.ReadCloser, rawBody *js.Value) js.Value {
+//   - if webSocket is not undefined, it is set to the Response as the client side of WebSocket.
+func newJSResponse(statusCode int, headers http.Header, contentLength int64, body io.ReadCloser, rawBody *js.Value, webSocket js.Value) js.Value {
 	status := statusCode
 	if status == 0 {
 		status = http.StatusOK
@@ -46,6 +68,9 @@ func newJSResponse(statusCode int, headers http.Header, contentLength int64, bod
 	respInit.Set("status", status)
 	respInit.Set("statusText", http.StatusText(status))
 	respInit.Set("headers", ToJSHeader(headers))
+	if !webSocket.IsUndefined() {
+		respInit.Set("webSocket", webSocket)
+	}
 	if status == http.StatusSwitchingProtocols ||
 		status == http.StatusNoContent ||
 		status == http.StatusResetContent ||
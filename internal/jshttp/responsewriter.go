@@ -18,6 +18,9 @@ type ResponseWriter struct {
 	ReadyCh     chan struct{}
 	Once        sync.Once
 	RawJSBody   *js.Value
+	// WebSocket is the client side of WebSocket set to the Response.
+	// If this is not undefined, the response body can't be written.
+	WebSocket js.Value
 }
 
 var (
@@ -33,7 +36,20 @@ func (w *ResponseWriter) Ready() {
 	})
 }
 
+// IsReady reports whether ResponseWriter is ready to be converted to Response.
+func (w *ResponseWriter) IsReady() bool {
+	select {
+	case <-w.ReadyCh:
+		return true
+	default:
+		return false
+	}
+}
+
 func (w *ResponseWriter) Write(data []byte) (n int, err error) {
+	if !w.WebSocket.IsUndefined() {
+		return 0, http.ErrHijacked
+	}
 	w.Ready()
 	return w.Writer.Write(data)
 }
@@ -63,5 +79,5 @@ func (w *ResponseWriter) Flush() {
 //   - Response: https://developer.mozilla.org/docs/Web/API/Response
 func (w *ResponseWriter) ToJSResponse() js.Value {
 	contentLength, _ := strconv.ParseInt(w.HeaderValue.Get("Content-Length"), 10, 64)
-	return newJSResponse(w.StatusCode, w.HeaderValue, contentLength, w.Reader, w.RawJSBody)
+	return newJSResponse(w.StatusCode, w.HeaderValue, contentLength, w.Reader, w.RawJSBody, w.WebSocket)
 }
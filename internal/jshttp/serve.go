@@ -0,0 +1,81 @@
+package jshttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"runtime/debug"
+	"syscall/js"
+	"time"
+
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/recovery"
+)
+
+// ServeOptions represents options of ServeRequest.
+type ServeOptions struct {
+	// Timeout is a duration after which the context of the request is cancelled.
+	// A zero or negative duration disables the timeout.
+	Timeout time.Duration
+	// WrapBody wraps the body of the Response before it is passed to the JS side. (optional)
+	WrapBody func(io.ReadCloser) io.ReadCloser
+}
+
+// ServeRequest serves a Request object by the handler, and returns Response object.
+//   - The context of the request is derived from ctx, and is cancelled when the client disconnects,
+//     the request times out, or the handler returns.
+//   - A panic in the handler is recovered and reported. If the response has not been sent yet,
+//     the panic response is written.
+func ServeRequest(ctx context.Context, handler http.Handler, reqObj js.Value, opts ServeOptions) (js.Value, error) {
+	req, err := ToRequest(reqObj)
+	if err != nil {
+		return js.Value{}, err
+	}
+	ctx, cancelSignal := jsutil.WithAbortSignal(ctx, reqObj.Get("signal"))
+	var cancelTimeout context.CancelFunc
+	if opts.Timeout > 0 {
+		ctx, cancelTimeout = context.WithTimeout(ctx, opts.Timeout)
+	} else {
+		ctx, cancelTimeout = context.WithCancel(ctx)
+	}
+	req = req.WithContext(ctx)
+	reader, writer := io.Pipe()
+	var body io.ReadCloser = reader
+	if opts.WrapBody != nil {
+		body = opts.WrapBody(body)
+	}
+	w := &ResponseWriter{
+		HeaderValue: http.Header{},
+		StatusCode:  http.StatusOK,
+		Reader:      body,
+		Writer:      writer,
+		ReadyCh:     make(chan struct{}),
+	}
+	go func() {
+		defer cancelSignal()
+		defer cancelTimeout()
+		defer w.Ready()
+		defer writer.Close()
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v != http.ErrAbortHandler {
+				recovery.Report(req, v, debug.Stack())
+			}
+			if w.IsReady() || v == http.ErrAbortHandler {
+				// The response has already been sent or must be aborted, so the body ends with an error.
+				writer.CloseWithError(recovery.Error(v))
+				return
+			}
+			w.HeaderValue = http.Header{}
+			w.StatusCode = http.StatusOK
+			w.RawJSBody = nil
+			recovery.WriteResponse(w, req, v)
+		}()
+		handler.ServeHTTP(w, req)
+	}()
+	<-w.ReadyCh
+	return w.ToJSResponse(), nil
+}
@@ -0,0 +1,54 @@
+package jshttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/recovery"
+)
+
+func TestServeRequest_Panic(t *testing.T) {
+	recovery.SetHook(func(*http.Request, any, []byte) {})
+	t.Cleanup(func() { recovery.SetHook(nil) })
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-Partial", "true")
+		w.WriteHeader(http.StatusAccepted)
+		panic("boom")
+	})
+	resObj, err := ServeRequest(context.Background(), handler, jsutil.RequestClass.New("https://example.com/"), ServeOptions{})
+	if err != nil {
+		t.Fatalf("ServeRequest() error = %v", err)
+	}
+	if got := resObj.Get("status").Int(); got != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", got, http.StatusInternalServerError)
+	}
+	if got := resObj.Get("headers").Call("get", "X-Partial"); !got.IsNull() {
+		t.Errorf("X-Partial header = %v, want null", got)
+	}
+}
+
+func TestServeRequest_PanicAfterWrite(t *testing.T) {
+	recovery.SetHook(func(*http.Request, any, []byte) {})
+	t.Cleanup(func() { recovery.SetHook(nil) })
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, "partial")
+		panic("boom")
+	})
+	resObj, err := ServeRequest(context.Background(), handler, jsutil.RequestClass.New("https://example.com/"), ServeOptions{})
+	if err != nil {
+		t.Fatalf("ServeRequest() error = %v", err)
+	}
+	if got := resObj.Get("status").Int(); got != http.StatusOK {
+		t.Errorf("status = %d, want %d", got, http.StatusOK)
+	}
+	// the body already sent ends with the error instead of the panic response.
+	if _, err := jsutil.AwaitPromise(resObj.Call("text")); err == nil || !strings.Contains(err.Error(), "panic: boom") {
+		t.Errorf("reading body error = %v, want panic: boom", err)
+	}
+}
@@ -0,0 +1,44 @@
+package jshttp
+
+import (
+	"net/http"
+	"syscall/js"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// unwrapResponseWriter finds *ResponseWriter by unwrapping the given http.ResponseWriter.
+// The wrapper of http.ResponseWriter can provide `Unwrap() http.ResponseWriter` like http.ResponseController.
+func unwrapResponseWriter(w http.ResponseWriter) (*ResponseWriter, bool) {
+	for {
+		switch v := w.(type) {
+		case *ResponseWriter:
+			return v, true
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = v.Unwrap()
+		default:
+			return nil, false
+		}
+	}
+}
+
+// UpgradeToWebSocket creates a WebSocketPair, calls accept with its server side,
+// and sets its client side to the 101 Switching Protocols response.
+//   - returns false if w can't carry WebSocket or WebSocketPair is not available.
+//   - accept must accept the server side of WebSocket.
+//   - if accept returns error, the response is not upgraded and the error is returned.
+func UpgradeToWebSocket(w http.ResponseWriter, accept func(server js.Value) error) (bool, error) {
+	rw, ok := unwrapResponseWriter(w)
+	if !ok || jsutil.MaybeWebSocketPairClass.IsUndefined() {
+		return false, nil
+	}
+	pair := jsutil.MaybeWebSocketPairClass.New()
+	client, server := pair.Get("0"), pair.Get("1")
+	if err := accept(server); err != nil {
+		return true, err
+	}
+	rw.WebSocket = client
+	rw.WriteHeader(http.StatusSwitchingProtocols)
+	rw.Ready()
+	return true, nil
+}
@@ -1,7 +1,11 @@
 package jsutil
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"syscall/js"
 	"time"
 )
@@ -15,18 +19,30 @@ var (
 	ResponseClass          = js.Global().Get("Response")
 	HeadersClass           = js.Global().Get("Headers")
 	ArrayClass             = js.Global().Get("Array")
+	ArrayBufferClass       = js.Global().Get("ArrayBuffer")
 	Uint8ArrayClass        = js.Global().Get("Uint8Array")
 	Uint8ClampedArrayClass = js.Global().Get("Uint8ClampedArray")
 	ErrorClass             = js.Global().Get("Error")
 	ReadableStreamClass    = js.Global().Get("ReadableStream")
 	DateClass              = js.Global().Get("Date")
+	AbortControllerClass   = js.Global().Get("AbortController")
+	JSONObject             = js.Global().Get("JSON")
 	Null                   = js.ValueOf(nil)
 	// MaybeFixedLengthStreamClass is a class for FixedLengthStream.
 	// * This class is only available in Cloudflare Workers.
 	// * If this class is not available, the value will be undefined.
 	MaybeFixedLengthStreamClass = js.Global().Get("FixedLengthStream")
+	// MaybeWebSocketPairClass is a class for WebSocketPair.
+	// * This class is only available in Cloudflare Workers.
+	// * If this class is not available, the value will be undefined.
+	MaybeWebSocketPairClass = js.Global().Get("WebSocketPair")
 )
 
+// IsInstanceReused reports whether the JS side keeps this instance resident and reuses it across events.
+func IsInstanceReused() bool {
+	return RuntimeContext.Get("reuseInstance").Truthy()
+}
+
 func NewObject() js.Value {
 	return ObjectClass.New()
 }
@@ -56,7 +72,28 @@ func ArrayFrom(v js.Value) js.Value {
 	return ArrayClass.Call("from", v)
 }
 
+// ErrEventLoopBlocked is returned when a Promise is awaited while the JS event loop is blocked by BlockEventLoop.
+// The Promise can't be settled until the event loop is unblocked, so awaiting it would never return.
+var ErrEventLoopBlocked = errors.New("promise can't be awaited while the JS event loop is blocked by a synchronous callback")
+
+// eventLoopBlocks is the number of synchronous callbacks blocking the JS event loop.
+var eventLoopBlocks atomic.Int32
+
+// BlockEventLoop marks the JS event loop as blocked until the returned function is called.
+// This must be called by a synchronous JS callback which waits for other goroutines.
+// While the event loop is blocked, AwaitPromise and AwaitPromiseContext return ErrEventLoopBlocked.
+func BlockEventLoop() (unblock func()) {
+	eventLoopBlocks.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() { eventLoopBlocks.Add(-1) })
+	}
+}
+
 func AwaitPromise(promiseVal js.Value) (js.Value, error) {
+	if eventLoopBlocks.Load() > 0 {
+		return js.Value{}, ErrEventLoopBlocked
+	}
 	resultCh := make(chan js.Value)
 	errCh := make(chan error)
 	var then, catch js.Func
@@ -69,7 +106,7 @@ func AwaitPromise(promiseVal js.Value) (js.Value, error) {
 	catch = js.FuncOf(func(_ js.Value, args []js.Value) any {
 		defer catch.Release()
 		result := args[0]
-		errCh <- fmt.Errorf("failed on promise: %s", result.Call("toString").String())
+		errCh <- fmt.Errorf("failed on promise: %s", js.Global().Get("String").Invoke(result).String())
 		return js.Undefined()
 	})
 	promiseVal.Call("then", then).Call("catch", catch)
@@ -81,6 +118,95 @@ func AwaitPromise(promiseVal js.Value) (js.Value, error) {
 	}
 }
 
+// settlePromise calls handler.fn with whether the promise is fulfilled and its result.
+// handler.fn is looked up on settlement, so removing it makes a late settlement a no-op.
+var settlePromise = js.Global().Get("Function").New("promise", "handler", `
+	promise.then(
+		(result) => handler.fn && handler.fn(true, result),
+		(reason) => handler.fn && handler.fn(false, reason),
+	);
+`)
+
+// AwaitPromiseContext waits for the promise like AwaitPromise, but returns ctx.Err() when ctx is done before the promise is settled.
+//   - The promise itself is not cancelled.
+func AwaitPromiseContext(ctx context.Context, promiseVal js.Value) (js.Value, error) {
+	if err := ctx.Err(); err != nil {
+		return js.Value{}, err
+	}
+	if eventLoopBlocks.Load() > 0 {
+		return js.Value{}, ErrEventLoopBlocked
+	}
+	// channels are buffered not to block JS callbacks.
+	resultCh := make(chan js.Value, 1)
+	errCh := make(chan error, 1)
+	fn := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		if args[0].Bool() {
+			resultCh <- args[1]
+		} else {
+			errCh <- fmt.Errorf("failed on promise: %s", js.Global().Get("String").Invoke(args[1]).String())
+		}
+		return js.Undefined()
+	})
+	handler := NewObject()
+	handler.Set("fn", fn)
+	defer func() {
+		handler.Delete("fn")
+		fn.Release()
+	}()
+	settlePromise.Invoke(promiseVal, handler)
+	select {
+	case result := <-resultCh:
+		return result, nil
+	case err := <-errCh:
+		return js.Value{}, err
+	case <-ctx.Done():
+		return js.Value{}, ctx.Err()
+	}
+}
+
+// NewAbortSignal returns AbortSignal which is aborted when ctx is done.
+//   - If ctx is never done, the returned signal is never aborted.
+//   - Calling stop stops aborting the signal, and releases the resources associated with it.
+//     stop must be called when the signal is no longer used. It reports the same as the stop function of context.AfterFunc.
+func NewAbortSignal(ctx context.Context) (signal js.Value, stop func() bool) {
+	controller := AbortControllerClass.New()
+	if ctx.Err() != nil {
+		controller.Call("abort", Error(context.Cause(ctx).Error()))
+		return controller.Get("signal"), func() bool { return false }
+	}
+	stop = context.AfterFunc(ctx, func() {
+		controller.Call("abort", Error(context.Cause(ctx).Error()))
+	})
+	return controller.Get("signal"), stop
+}
+
+// WithAbortSignal returns a copy of ctx which is cancelled when the given AbortSignal is aborted.
+//   - If the signal is undefined or null, the returned context is only cancelled by the returned CancelFunc.
+//   - The returned CancelFunc must be called to release resources.
+func WithAbortSignal(ctx context.Context, signal js.Value) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	if signal.IsUndefined() || signal.IsNull() {
+		return ctx, cancel
+	}
+	if signal.Get("aborted").Bool() {
+		cancel()
+		return ctx, cancel
+	}
+	onAbort := js.FuncOf(func(js.Value, []js.Value) any {
+		cancel()
+		return js.Undefined()
+	})
+	signal.Call("addEventListener", "abort", onAbort)
+	var once sync.Once
+	return ctx, func() {
+		cancel()
+		once.Do(func() {
+			signal.Call("removeEventListener", "abort", onAbort)
+			onAbort.Release()
+		})
+	}
+}
+
 // StrRecordToMap converts JavaScript side's Record<string, string> into map[string]string.
 func StrRecordToMap(v js.Value) map[string]string {
 	if v.IsUndefined() || v.IsNull() {
@@ -0,0 +1,139 @@
+package jsutil
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"syscall/js"
+	"testing"
+	"time"
+)
+
+func TestWithAbortSignal(t *testing.T) {
+	controller := AbortControllerClass.New()
+	ctx, cancel := WithAbortSignal(context.Background(), controller.Get("signal"))
+	defer cancel()
+
+	if err := ctx.Err(); err != nil {
+		t.Fatalf("context must not be cancelled before abort, got %v", err)
+	}
+	controller.Call("abort")
+	<-ctx.Done()
+	if err := ctx.Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("ctx.Err() = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestWithAbortSignal_AlreadyAborted(t *testing.T) {
+	controller := AbortControllerClass.New()
+	controller.Call("abort")
+	ctx, cancel := WithAbortSignal(context.Background(), controller.Get("signal"))
+	defer cancel()
+
+	if err := ctx.Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("ctx.Err() = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestNewAbortSignal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signal, stop := NewAbortSignal(ctx)
+	defer stop()
+	if signal.Get("aborted").Bool() {
+		t.Fatal("signal must not be aborted before cancel")
+	}
+
+	aborted := make(chan struct{})
+	signal.Call("addEventListener", "abort", js.FuncOf(func(js.Value, []js.Value) any {
+		close(aborted)
+		return js.Undefined()
+	}))
+	cancel()
+	<-aborted
+}
+
+func TestNewAbortSignal_Stop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signal, stop := NewAbortSignal(ctx)
+	if !stop() {
+		t.Fatal("stop() = false, want true before cancel")
+	}
+	cancel()
+	if signal.Get("aborted").Bool() {
+		t.Error("signal must not be aborted after stop")
+	}
+}
+
+func TestAwaitPromiseContext(t *testing.T) {
+	t.Run("resolved", func(t *testing.T) {
+		got, err := AwaitPromiseContext(context.Background(), PromiseClass.Call("resolve", "ok"))
+		if err != nil {
+			t.Fatalf("AwaitPromiseContext() error = %v", err)
+		}
+		if got.String() != "ok" {
+			t.Errorf("AwaitPromiseContext() = %v, want %v", got, "ok")
+		}
+	})
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		// never settled promise
+		promise := NewPromise(js.FuncOf(func(js.Value, []js.Value) any {
+			return js.Undefined()
+		}))
+		cancel()
+		_, err := AwaitPromiseContext(ctx, promise)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("AwaitPromiseContext() error = %v, want %v", err, context.Canceled)
+		}
+	})
+	t.Run("rejected", func(t *testing.T) {
+		_, err := AwaitPromiseContext(context.Background(), PromiseClass.Call("reject", Error("boom")))
+		if err == nil || !strings.Contains(err.Error(), "boom") {
+			t.Errorf("AwaitPromiseContext() error = %v, want boom", err)
+		}
+	})
+	t.Run("settled after cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var resolve js.Value
+		cb := js.FuncOf(func(_ js.Value, args []js.Value) any {
+			resolve = args[0]
+			return js.Undefined()
+		})
+		defer cb.Release()
+		promise := NewPromise(cb)
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+		}()
+		if _, err := AwaitPromiseContext(ctx, promise); !errors.Is(err, context.Canceled) {
+			t.Fatalf("AwaitPromiseContext() error = %v, want %v", err, context.Canceled)
+		}
+		// a late settlement must not call the released callback.
+		resolve.Invoke("late")
+		if _, err := AwaitPromise(promise); err != nil {
+			t.Fatalf("AwaitPromise() error = %v", err)
+		}
+	})
+}
+
+func TestBlockEventLoop(t *testing.T) {
+	promise := PromiseClass.Call("resolve", "ok")
+	unblock := BlockEventLoop()
+	if _, err := AwaitPromise(promise); !errors.Is(err, ErrEventLoopBlocked) {
+		t.Errorf("AwaitPromise() error = %v, want %v", err, ErrEventLoopBlocked)
+	}
+	if _, err := AwaitPromiseContext(context.Background(), promise); !errors.Is(err, ErrEventLoopBlocked) {
+		t.Errorf("AwaitPromiseContext() error = %v, want %v", err, ErrEventLoopBlocked)
+	}
+	unblock()
+	unblock() // calling twice must be a no-op.
+	if got, err := AwaitPromise(promise); err != nil || got.String() != "ok" {
+		t.Errorf("AwaitPromise() = %v, %v, want ok", got, err)
+	}
+
+	unblock = BlockEventLoop()
+	defer unblock()
+	if _, err := AwaitPromise(promise); !errors.Is(err, ErrEventLoopBlocked) {
+		t.Errorf("AwaitPromise() error = %v, want %v", err, ErrEventLoopBlocked)
+	}
+}
@@ -54,7 +54,7 @@ func (sr *readableStreamToReadCloser) Read(p []byte) (n int, err error) {
 		catch = js.FuncOf(func(_ js.Value, args []js.Value) any {
 			defer catch.Release()
 			result := args[0]
-			errCh <- fmt.Errorf("JavaScript error on read: %s", result.Call("toString").String())
+			errCh <- fmt.Errorf("JavaScript error on read: %s", js.Global().Get("String").Invoke(result).String())
 			return js.Undefined()
 		})
 		promise.Call("then", then).Call("catch", catch)
@@ -96,7 +96,12 @@ func (sr *readableStreamToReadCloser) WriteTo(w io.Writer) (n int64, err error)
 	return io.Copy(w, &readerWrapper{sr})
 }
 
+// GetRawJSBody returns the underlying ReadableStream.
+//   - if the stream has been read by Read, undefined is returned because the stream is locked and partly consumed.
 func (sr *readableStreamToReadCloser) GetRawJSBody() js.Value {
+	if sr.streamReader != nil {
+		return js.Undefined()
+	}
 	return sr.stream
 }
 
@@ -207,26 +212,65 @@ func ConvertReaderToReadableStream(reader io.ReadCloser) js.Value {
 }
 
 // ConvertReaderToFixedLengthStream converts io.ReadCloser to TransformStream.
+//   - each chunk is written after the stream is ready to accept it, so the whole body is not queued in JS memory.
 func ConvertReaderToFixedLengthStream(rc io.ReadCloser, size int64) js.Value {
 	stream := MaybeFixedLengthStreamClass.New(js.ValueOf(size))
 	go func(writer js.Value) {
 		defer rc.Close()
 
-		chunk := make([]byte, min(size, defaultChunkSize))
-		AwaitPromise(writer.Get("ready"))
+		// the chunk must not be empty even if size is 0, otherwise Read never proceeds.
+		// FixedLengthStream errors if more bytes than size are written.
+		chunk := make([]byte, max(min(size, defaultChunkSize), 1))
 		for {
+			if _, err := AwaitPromise(writer.Get("ready")); err != nil {
+				// the stream has been errored or cancelled.
+				return
+			}
 			n, err := rc.Read(chunk)
 			if n > 0 {
 				b := Uint8ArrayClass.New(n)
 				js.CopyBytesToJS(b, chunk[:n])
-				writer.Call("write", b)
+				writer.Call("write", b).Call("catch", noop)
+			}
+			if err == io.EOF {
+				writer.Call("close").Call("catch", noop)
+				return
 			}
 			if err != nil {
-				AwaitPromise(writer.Get("ready"))
-				writer.Call("close")
+				writer.Call("abort", Error(err.Error())).Call("catch", noop)
 				return
 			}
 		}
 	}(stream.Get("writable").Call("getWriter"))
 	return stream.Get("readable")
 }
+
+// noop is used to handle the rejections of the promises whose errors are reported by another way.
+var noop = js.Global().Get("Function").New("")
+
+// ConvertReaderToStreamBody converts r into a ReadableStream which can be passed to the runtime APIs
+// without buffering the whole body in Go memory.
+//   - if r implements RawJSBodyGetter and has not been read (e.g. an unread incoming request body), its raw JS stream is used.
+//     if size is not negative and FixedLengthStream is available, the stream is piped through FixedLengthStream of the size.
+//   - otherwise, if FixedLengthStream is available and size is not negative, r is streamed through FixedLengthStream of the size.
+//   - FixedLengthStream errors if the length of the body does not match the size.
+//   - ok is false if none of them is possible.
+func ConvertReaderToStreamBody(r io.Reader, size int64) (stream js.Value, ok bool) {
+	hasFixedLengthStream := !MaybeFixedLengthStreamClass.IsUndefined()
+	if g, ok := r.(RawJSBodyGetter); ok {
+		if body := g.GetRawJSBody(); body.Truthy() {
+			if size < 0 || !hasFixedLengthStream {
+				return body, true
+			}
+			return body.Call("pipeThrough", MaybeFixedLengthStreamClass.New(js.ValueOf(size))), true
+		}
+	}
+	if !hasFixedLengthStream || size < 0 {
+		return js.Value{}, false
+	}
+	rc, isCloser := r.(io.ReadCloser)
+	if !isCloser {
+		rc = io.NopCloser(r)
+	}
+	return ConvertReaderToFixedLengthStream(rc, size), true
+}
@@ -0,0 +1,149 @@
+package jsutil
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"syscall/js"
+	"testing"
+	"time"
+)
+
+// withFakeFixedLengthStream replaces FixedLengthStream, which is only available in Cloudflare Workers,
+// with TransformStream which errors if the length of the body does not match the size.
+func withFakeFixedLengthStream(t *testing.T) {
+	t.Helper()
+	orig := MaybeFixedLengthStreamClass
+	MaybeFixedLengthStreamClass = js.Global().Get("Function").New("size", `
+		let length = 0;
+		return new TransformStream({
+			transform(chunk, controller) {
+				length += chunk.byteLength;
+				if (length > size) {
+					throw new TypeError("body is longer than the expected length");
+				}
+				controller.enqueue(chunk);
+			},
+			flush() {
+				if (length !== size) {
+					throw new TypeError("body is shorter than the expected length");
+				}
+			},
+		});
+	`)
+	t.Cleanup(func() { MaybeFixedLengthStreamClass = orig })
+}
+
+func readStream(t *testing.T, stream js.Value) string {
+	t.Helper()
+	b, err := io.ReadAll(ConvertReadableStreamToReadCloser(stream))
+	if err != nil {
+		t.Fatalf("failed to read stream: %v", err)
+	}
+	return string(b)
+}
+
+func TestConvertReaderToStreamBody_RawJSBody(t *testing.T) {
+	withFakeFixedLengthStream(t)
+	raw := ResponseClass.New("hello").Get("body")
+	stream, ok := ConvertReaderToStreamBody(ConvertReadableStreamToReadCloser(raw), -1)
+	if !ok {
+		t.Fatal("ConvertReaderToStreamBody() ok = false, want true")
+	}
+	if !stream.Equal(raw) {
+		t.Error("ConvertReaderToStreamBody() with unknown size did not return the raw stream")
+	}
+}
+
+func TestConvertReaderToStreamBody_SizedRawJSBody(t *testing.T) {
+	withFakeFixedLengthStream(t)
+	raw := ResponseClass.New("hello").Get("body")
+	stream, ok := ConvertReaderToStreamBody(ConvertReadableStreamToReadCloser(raw), 5)
+	if !ok {
+		t.Fatal("ConvertReaderToStreamBody() ok = false, want true")
+	}
+	if got := readStream(t, stream); got != "hello" {
+		t.Errorf("stream = %q, want %q", got, "hello")
+	}
+	if !raw.Get("locked").Bool() {
+		t.Error("ConvertReaderToStreamBody() did not pipe the raw stream")
+	}
+}
+
+func TestConvertReaderToStreamBody_PartiallyReadRawJSBody(t *testing.T) {
+	withFakeFixedLengthStream(t)
+	rc := ConvertReadableStreamToReadCloser(ResponseClass.New("hello").Get("body"))
+	if _, err := io.ReadFull(rc, make([]byte, 2)); err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	stream, ok := ConvertReaderToStreamBody(rc, 3)
+	if !ok {
+		t.Fatal("ConvertReaderToStreamBody() ok = false, want true")
+	}
+	if got := readStream(t, stream); got != "llo" {
+		t.Errorf("stream = %q, want %q", got, "llo")
+	}
+}
+
+func TestConvertReaderToStreamBody_FixedLength(t *testing.T) {
+	withFakeFixedLengthStream(t)
+	stream, ok := ConvertReaderToStreamBody(bytes.NewReader([]byte("hello")), 5)
+	if !ok {
+		t.Fatal("ConvertReaderToStreamBody() ok = false, want true")
+	}
+	if got := readStream(t, stream); got != "hello" {
+		t.Errorf("stream = %q, want %q", got, "hello")
+	}
+}
+
+func TestConvertReaderToStreamBody_Unavailable(t *testing.T) {
+	withFakeFixedLengthStream(t)
+	if _, ok := ConvertReaderToStreamBody(bytes.NewReader(nil), -1); ok {
+		t.Error("ConvertReaderToStreamBody() with unknown size ok = true, want false")
+	}
+	read := ConvertReadableStreamToReadCloser(ResponseClass.New("hello").Get("body"))
+	if _, err := io.ReadFull(read, make([]byte, 2)); err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	if _, ok := ConvertReaderToStreamBody(read, -1); ok {
+		t.Error("ConvertReaderToStreamBody() of partly read body with unknown size ok = true, want false")
+	}
+	MaybeFixedLengthStreamClass = js.Undefined()
+	if _, ok := ConvertReaderToStreamBody(bytes.NewReader(nil), 0); ok {
+		t.Error("ConvertReaderToStreamBody() without FixedLengthStream ok = true, want false")
+	}
+}
+
+func TestConvertReaderToFixedLengthStream_SizeMismatch(t *testing.T) {
+	withFakeFixedLengthStream(t)
+	for name, size := range map[string]int64{"zero": 0, "shorter": 2, "longer": 5} {
+		t.Run(name, func(t *testing.T) {
+			stream := ConvertReaderToFixedLengthStream(io.NopCloser(strings.NewReader("abc")), size)
+			if _, err := io.ReadAll(ConvertReadableStreamToReadCloser(stream)); err == nil {
+				t.Error("reading stream succeeded, want error")
+			}
+		})
+	}
+}
+
+type countingReader struct {
+	reads int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	r.reads++
+	return len(p), nil
+}
+
+func TestConvertReaderToFixedLengthStream_Backpressure(t *testing.T) {
+	withFakeFixedLengthStream(t)
+	r := &countingReader{}
+	stream := ConvertReaderToFixedLengthStream(io.NopCloser(r), 100*defaultChunkSize)
+	// cancelling without reason rejects writer.ready with undefined, which must be handled.
+	defer stream.Call("cancel")
+	// the stream is not consumed, so the reader must not be read ahead.
+	time.Sleep(50 * time.Millisecond)
+	if r.reads > 3 {
+		t.Errorf("reader was read %d times before the stream was consumed", r.reads)
+	}
+}
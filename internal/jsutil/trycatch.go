@@ -6,11 +6,27 @@ import (
 )
 
 func TryCatch(fn js.Func) (js.Value, error) {
+	return tryCatch(fn.Value)
+}
+
+// TryCatchCall calls the method of obj with args, and returns the error thrown by the method.
+// Unlike TryCatch, errors thrown by JS are caught without going through a Go callback,
+// so this must be used for JS calls which may throw.
+//   - The method is called as obj[method](...args) in JS. Properties of the method (e.g. bind) are not used,
+//     since they may be resolved as remote properties on RPC stubs.
+func TryCatchCall(obj js.Value, method string, args ...any) (js.Value, error) {
+	return tryCatch(methodCaller.Invoke(obj, method, args))
+}
+
+// methodCaller returns a function which calls obj[method] with args.
+var methodCaller = js.Global().Get("Function").New("obj", "method", "args", "return () => obj[method](...args);")
+
+func tryCatch(fn js.Value) (js.Value, error) {
 	fnResultVal := js.Global().Call("tryCatch", fn)
 	resultVal := fnResultVal.Get("result")
 	errorVal := fnResultVal.Get("error")
 	if !errorVal.IsUndefined() {
-		return js.Value{}, errors.New(errorVal.String())
+		return js.Value{}, errors.New(js.Global().Get("String").Invoke(errorVal).String())
 	}
 	return resultVal, nil
 }
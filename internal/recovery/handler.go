@@ -0,0 +1,59 @@
+package recovery
+
+import (
+	"net/http"
+	"runtime/debug"
+)
+
+// Handler returns a http.Handler which recovers panics of h, reports them and writes the panic response.
+//   - If h has already written the response, the panic response is not written and the response is aborted
+//     by panicking with http.ErrAbortHandler.
+func Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rw := &responseWriter{ResponseWriter: w}
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				// net/http aborts the response silently.
+				panic(v)
+			}
+			Report(req, v, debug.Stack())
+			if rw.written {
+				panic(http.ErrAbortHandler)
+			}
+			WriteResponse(w, req, v)
+		}()
+		h.ServeHTTP(rw, req)
+	})
+}
+
+// responseWriter records whether the response has been written.
+type responseWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+var _ http.Flusher = (*responseWriter)(nil)
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	w.written = true
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseWriter) Flush() {
+	w.written = true
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap allows http.ResponseController to access the underlying http.ResponseWriter.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,55 @@
+package recovery
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_Panic(t *testing.T) {
+	var reported any
+	SetHook(func(_ *http.Request, v any, _ []byte) { reported = v })
+	t.Cleanup(func() { SetHook(nil) })
+
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-Partial", "true")
+		panic("boom")
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "https://example.com/", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if reported != "boom" {
+		t.Errorf("reported = %v, want %v", reported, "boom")
+	}
+}
+
+func TestHandler_PanicAfterWrite(t *testing.T) {
+	SetHook(func(*http.Request, any, []byte) {})
+	t.Cleanup(func() { SetHook(nil) })
+
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, "partial")
+		panic("boom")
+	}))
+	rec := httptest.NewRecorder()
+	func() {
+		defer func() {
+			if v := recover(); v != http.ErrAbortHandler {
+				t.Errorf("recovered = %v, want %v", v, http.ErrAbortHandler)
+			}
+		}()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "https://example.com/", nil))
+	}()
+
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+	if got := rec.Body.String(); got != "partial" {
+		t.Errorf("body = %q, want %q", got, "partial")
+	}
+}
@@ -0,0 +1,83 @@
+package recovery
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+)
+
+// Hook is a function called with a recovered panic value and the Go stack of the panicked goroutine.
+//   - req is nil when the panic did not happen while handling an HTTP request.
+type Hook func(req *http.Request, v any, stack []byte)
+
+// hookMu guards hook and response.
+var (
+	hookMu   sync.RWMutex
+	hook     Hook
+	response ResponseFunc = defaultResponse
+)
+
+// SetHook sets the Hook called by Report.
+func SetHook(h Hook) {
+	hookMu.Lock()
+	defer hookMu.Unlock()
+	hook = h
+}
+
+// Report logs a recovered panic value with its stack in a structured way, then calls the Hook.
+func Report(req *http.Request, v any, stack []byte) {
+	attrs := []any{
+		slog.String("panic", fmt.Sprint(v)),
+		slog.String("stack", string(stack)),
+	}
+	if req != nil {
+		attrs = append(attrs,
+			slog.String("method", req.Method),
+			slog.String("url", req.URL.String()),
+		)
+	}
+	slog.Error("workers: panic recovered", attrs...)
+
+	hookMu.RLock()
+	h := hook
+	hookMu.RUnlock()
+	if h != nil {
+		h(req, v, stack)
+	}
+}
+
+// Error converts a recovered panic value into an error.
+func Error(v any) error {
+	if err, ok := v.(error); ok {
+		return fmt.Errorf("panic: %w", err)
+	}
+	return fmt.Errorf("panic: %v", v)
+}
+
+// ResponseFunc writes the response for a request whose handler panicked.
+type ResponseFunc func(w http.ResponseWriter, req *http.Request, v any)
+
+// defaultResponse responds with 500 Internal Server Error.
+func defaultResponse(w http.ResponseWriter, _ *http.Request, _ any) {
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+// SetResponse sets the ResponseFunc used by WriteResponse.
+//   - If nil is given, 500 Internal Server Error is responded.
+func SetResponse(fn ResponseFunc) {
+	if fn == nil {
+		fn = defaultResponse
+	}
+	hookMu.Lock()
+	defer hookMu.Unlock()
+	response = fn
+}
+
+// WriteResponse writes the response for a request whose handler panicked with v.
+func WriteResponse(w http.ResponseWriter, req *http.Request, v any) {
+	hookMu.RLock()
+	fn := response
+	hookMu.RUnlock()
+	fn(w, req, v)
+}
@@ -0,0 +1,83 @@
+package recovery
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	var (
+		gotReq   *http.Request
+		gotValue any
+		gotStack []byte
+	)
+	SetHook(func(req *http.Request, v any, stack []byte) {
+		gotReq, gotValue, gotStack = req, v, stack
+	})
+	t.Cleanup(func() { SetHook(nil) })
+
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+	Report(req, "boom", []byte("stack"))
+
+	if gotReq != req {
+		t.Errorf("req = %v, want %v", gotReq, req)
+	}
+	if gotValue != "boom" {
+		t.Errorf("v = %v, want %v", gotValue, "boom")
+	}
+	if string(gotStack) != "stack" {
+		t.Errorf("stack = %q, want %q", gotStack, "stack")
+	}
+}
+
+func TestError(t *testing.T) {
+	errBoom := errors.New("boom")
+	tests := map[string]struct {
+		v    any
+		want string
+	}{
+		"string value": {
+			v:    "boom",
+			want: "panic: boom",
+		},
+		"error value": {
+			v:    errBoom,
+			want: "panic: boom",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := Error(tc.v)
+			if err.Error() != tc.want {
+				t.Errorf("Error() = %q, want %q", err.Error(), tc.want)
+			}
+		})
+	}
+	if err := Error(errBoom); !errors.Is(err, errBoom) {
+		t.Errorf("Error() must wrap the error value, got %v", err)
+	}
+}
+
+func TestSetResponse_Concurrent(t *testing.T) {
+	defer SetResponse(nil)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			SetResponse(func(w http.ResponseWriter, _ *http.Request, _ any) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			})
+			SetResponse(nil)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		rec := httptest.NewRecorder()
+		WriteResponse(rec, httptest.NewRequest(http.MethodGet, "/", nil), "boom")
+		if rec.Code != http.StatusInternalServerError && rec.Code != http.StatusServiceUnavailable {
+			t.Fatalf("status = %d, want 500 or 503", rec.Code)
+		}
+	}
+	<-done
+}
@@ -7,11 +7,18 @@ import (
 
 type (
 	contextKeyTriggerObj struct{}
+	contextKeyRuntimeObj struct{}
 )
 
-func New(ctx context.Context, triggerObj js.Value) context.Context {
+// New returns a context which holds the trigger object of the event and the runtime context object given by the JS side.
+func New(ctx context.Context, triggerObj, runtimeObj js.Value) context.Context {
 	ctx = context.WithValue(ctx, contextKeyTriggerObj{}, triggerObj)
-	return ctx
+	return WithRuntimeObj(ctx, runtimeObj)
+}
+
+// WithRuntimeObj returns a copy of ctx which holds the given runtime context object.
+func WithRuntimeObj(ctx context.Context, runtimeObj js.Value) context.Context {
+	return context.WithValue(ctx, contextKeyRuntimeObj{}, runtimeObj)
 }
 
 // MustExtractTriggerObj extracts trigger object from context.
@@ -23,3 +30,10 @@ func MustExtractTriggerObj(ctx context.Context) js.Value {
 	}
 	return v
 }
+
+// ExtractRuntimeObj extracts runtime context object from context.
+// If runtime context object was not found, ok is false.
+func ExtractRuntimeObj(ctx context.Context) (v js.Value, ok bool) {
+	v, ok = ctx.Value(contextKeyRuntimeObj{}).(js.Value)
+	return v, ok
+}
@@ -0,0 +1,34 @@
+package workers
+
+import (
+	"net/http"
+
+	"github.com/syumai/workers/internal/recovery"
+)
+
+// PanicHook is a function called when a handler panics.
+//   - v is the recovered panic value, and stack is the Go stack of the panicked goroutine.
+//   - req is nil when the panic did not happen while handling an HTTP request (e.g. in cron tasks).
+type PanicHook func(req *http.Request, v any, stack []byte)
+
+// OnPanic sets the PanicHook which is called after a panic is recovered and logged.
+// This is useful to forward panics to an error tracker.
+//   - This function must be called before Serve.
+func OnPanic(hook PanicHook) {
+	recovery.SetHook(recovery.Hook(hook))
+}
+
+// PanicResponseFunc writes the response for a request whose handler panicked.
+type PanicResponseFunc func(w http.ResponseWriter, req *http.Request, v any)
+
+// SetPanicResponse sets the PanicResponseFunc which writes the response when a handler panics.
+//   - The response is written only if the handler has not started writing the response body yet.
+//   - If nil is given, 500 Internal Server Error is responded. This is the default.
+//   - This function must be called before Serve.
+func SetPanicResponse(fn PanicResponseFunc) {
+	if fn == nil {
+		recovery.SetResponse(nil)
+		return
+	}
+	recovery.SetResponse(recovery.ResponseFunc(fn))
+}
@@ -0,0 +1,20 @@
+//go:build !js
+
+package workers
+
+import (
+	"context"
+
+	"github.com/syumai/workers/cloudflare/codec"
+)
+
+// RPCFunc is a function exported as an RPC method of a WorkerEntrypoint.
+//   - args are the arguments given by the caller. Each of them can be decoded by Decode.
+//   - The returned value is encoded by codec.StructuredClone and returned to the caller.
+//   - The returned error is thrown to the caller.
+type RPCFunc func(ctx context.Context, args []*codec.Value) (any, error)
+
+// ExportRPC exports methods as the RPC methods of the WorkerEntrypoint class named name.
+// RPC methods can't be called in non-JS environments, so this function does nothing.
+// This function is implemented for non-JS environments for debugging purposes.
+func ExportRPC(string, map[string]RPCFunc) {}
@@ -0,0 +1,100 @@
+//go:build js && wasm
+
+package workers
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare/codec"
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/recovery"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+// RPCFunc is a function exported as an RPC method of a WorkerEntrypoint.
+//   - args are the arguments given by the caller. Each of them can be decoded by Decode.
+//   - The returned value is encoded by codec.StructuredClone and returned to the caller.
+//   - The returned error is thrown to the caller.
+type RPCFunc func(ctx context.Context, args []*codec.Value) (any, error)
+
+var (
+	rpcEntrypointsMu sync.RWMutex
+	rpcEntrypoints   = map[string]map[string]RPCFunc{}
+)
+
+// ExportRPC exports methods as the RPC methods of the WorkerEntrypoint class named name.
+//   - The class and its methods must be generated into worker.mjs by workers-assets-gen with the `-rpc` flag
+//     (e.g. `-rpc=Greeter.hello,Greeter.goodbye`).
+//   - Other Workers can call the methods through a service binding with `entrypoint = "Greeter"`.
+//   - This function must be called before Serve (or Ready).
+//   - https://developers.cloudflare.com/workers/runtime-apis/rpc/
+func ExportRPC(name string, methods map[string]RPCFunc) {
+	rpcEntrypointsMu.Lock()
+	defer rpcEntrypointsMu.Unlock()
+	rpcEntrypoints[name] = methods
+}
+
+func getRPCFunc(entrypoint, method string) (RPCFunc, bool) {
+	rpcEntrypointsMu.RLock()
+	defer rpcEntrypointsMu.RUnlock()
+	fn, ok := rpcEntrypoints[entrypoint][method]
+	return fn, ok
+}
+
+// callRPC calls the exported RPC method, and returns its encoded result.
+// A panic in the method is recovered, reported and returned as an error.
+func callRPC(entrypoint, method string, argsObj, runtimeObj js.Value) (_ js.Value, err error) {
+	fn, ok := getRPCFunc(entrypoint, method)
+	if !ok {
+		return js.Value{}, fmt.Errorf("rpc method is not exported: %s.%s", entrypoint, method)
+	}
+	defer func() {
+		if v := recover(); v != nil {
+			recovery.Report(nil, v, debug.Stack())
+			err = recovery.Error(v)
+		}
+	}()
+	args := make([]*codec.Value, argsObj.Length())
+	for i := range args {
+		args[i] = codec.NewValue(argsObj.Index(i), codec.StructuredClone)
+	}
+	ctx := runtimecontext.New(context.Background(), argsObj, runtimeObj)
+	result, err := fn(ctx, args)
+	if err != nil {
+		return js.Value{}, err
+	}
+	return codec.StructuredClone.Encode(result)
+}
+
+func init() {
+	callRPCCallback := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		if len(args) != 4 {
+			panic(fmt.Errorf("invalid number of arguments given to callRPC: %d", len(args)))
+		}
+		entrypoint := args[0].String()
+		method := args[1].String()
+		argsObj := args[2]
+		runtimeObj := args[3]
+		var cb js.Func
+		cb = js.FuncOf(func(_ js.Value, pArgs []js.Value) any {
+			defer cb.Release()
+			resolve := pArgs[0]
+			reject := pArgs[1]
+			go func() {
+				result, err := callRPC(entrypoint, method, argsObj, runtimeObj)
+				if err != nil {
+					reject.Invoke(jsutil.Error(err.Error()))
+					return
+				}
+				resolve.Invoke(result)
+			}()
+			return js.Undefined()
+		})
+		return jsutil.NewPromise(cb)
+	})
+	jsutil.Binding.Set("callRPC", callRPCCallback)
+}
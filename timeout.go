@@ -0,0 +1,25 @@
+package workers
+
+import (
+	"context"
+	"time"
+)
+
+// requestTimeout is a duration after which the context of each request is cancelled.
+var requestTimeout time.Duration
+
+// SetRequestTimeout sets the duration after which the context of each request is cancelled.
+//   - The context is also cancelled when the client disconnects, regardless of this setting.
+//   - A zero or negative duration disables the timeout. This is the default.
+//   - This function must be called before Serve.
+func SetRequestTimeout(d time.Duration) {
+	requestTimeout = d
+}
+
+// withRequestTimeout returns a copy of ctx which is cancelled after the configured request timeout.
+func withRequestTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if requestTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, requestTimeout)
+}